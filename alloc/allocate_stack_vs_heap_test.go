@@ -1,6 +1,10 @@
-package main
+package alloc
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+)
 
 type Foo struct {
 	foo int64
@@ -18,45 +22,52 @@ type Bar struct {
 var bts []byte
 
 func BenchmarkAllocateFooStack(b *testing.B) {
+	defer harness.RecordGC(b).Stop()
 	for i := 0; i < b.N; i++ {
-		func() Foo {
-			return Foo{}
-		}()
+		harness.Sink.Int64 = func() Foo {
+			return Foo{foo: int64(i)}
+		}().foo
 	}
 }
 
 func BenchmarkAllocateBarStack(b *testing.B) {
+	defer harness.RecordGC(b).Stop()
 	for i := 0; i < b.N; i++ {
-		func() Bar {
-			return Bar{}
-		}()
+		harness.Sink.Int64 = func() Bar {
+			return Bar{foo: int64(i)}
+		}().foo
 	}
 }
 
 func BenchmarkAllocateFooHeap(b *testing.B) {
+	defer harness.RecordGC(b).Stop()
 	for i := 0; i < b.N; i++ {
-		func() *Foo {
+		harness.Sink.Any = func() *Foo {
 			return new(Foo)
 		}()
 	}
 }
 
 func BenchmarkAllocateBarHeap(b *testing.B) {
+	defer harness.RecordGC(b).Stop()
 	for i := 0; i < b.N; i++ {
-		func() *Bar {
+		harness.Sink.Any = func() *Bar {
 			return new(Bar)
 		}()
 	}
 }
 
 func BenchmarkAllocateSliceHeapNoEscape(b *testing.B) {
+	defer harness.RecordGC(b).Stop()
 	for i := 0; i < b.N; i++ {
 		bts := make([]byte, 1024)
 		bts[0] = 1
+		harness.Sink.Byte = bts[i%len(bts)]
 	}
 }
 
 func BenchmarkAllocateSliceHeapEscape(b *testing.B) {
+	defer harness.RecordGC(b).Stop()
 	for i := 0; i < b.N; i++ {
 		bts = make([]byte, 1024)
 		bts[0] = 1
@@ -0,0 +1,55 @@
+package bitsets
+
+import (
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+	"github.com/willf/bitset"
+)
+
+// bitsetSizes are the numbers of consecutive bits set, from 0, in each
+// benchmark.
+var bitsetSizes = harness.Axis{Name: "bits", Values: []int{1000, 10000, 100000, 1000000}}
+
+func BenchmarkBitsetRoaringConsecutive(b *testing.B) {
+	harness.Sweep(b, []harness.Axis{bitsetSizes}, func(p harness.Params) func(b *testing.B) {
+		end := uint32(p.Int("bits"))
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rb := roaring.NewBitmap()
+				for i := uint32(0); i < end; i++ {
+					rb.Add(i)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkBitsetWillfConsecutive(b *testing.B) {
+	harness.Sweep(b, []harness.Axis{bitsetSizes}, func(p harness.Params) func(b *testing.B) {
+		end := uint(p.Int("bits"))
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b := bitset.New(end)
+				for i := uint(0); i < end; i++ {
+					b.Set(i)
+				}
+			}
+		}
+	})
+}
+
+// func benchmarkBitsetRoaringRandom(nums []uint32) {
+// 	rb := roaring.NewBitmap()
+// 	for _, num := range nums {
+// 		rb.Add(num)
+// 	}
+// }
+
+// func benchmarkBitsetWillfRandom(nums []uint) {
+// 	b := bitset.New(len(nums))
+// 	for _, num := range nums {
+// 		b.Set(num)
+// 	}
+// }
@@ -1,27 +0,0 @@
-package main
-
-import "testing"
-
-func BenchmarkSynchronousChannel(b *testing.B) {
-	ch := make(chan int)
-	go func() {
-		for i := 0; i < b.N; i++ {
-			ch <- i
-		}
-		close(ch)
-	}()
-	for _ = range ch {
-	}
-}
-
-func BenchmarkBufferedChannel(b *testing.B) {
-	ch := make(chan int, 128)
-	go func() {
-		for i := 0; i < b.N; i++ {
-			ch <- i
-		}
-		close(ch)
-	}()
-	for _ = range ch {
-	}
-}
@@ -0,0 +1,94 @@
+// Command asmview shows the compiled benchmark loops of each declared pair of
+// benchmark variants side by side.
+//
+// It compiles the test binary of each package declaring a pair, disassembles
+// the benchmarks with go tool objdump and extracts the loop each benchmark
+// runs b.N times, leaving out the instructions which only run the loop and
+// counting the rest. The pairs are read from variants.json, as for
+// cmd/compare.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/asmview [-file compiler/bit_tricks_test.go] [-html asm.html]
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/asm"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+	"github.com/jeromefroe/golang_benchmarks/internal/dce"
+)
+
+func main() {
+	var (
+		pairsPath = flag.String("pairs", "variants.json", "path of the declared variant pairs")
+		dir       = flag.String("dir", ".", "directory containing the benchmark packages")
+		file      = flag.String("file", "", "only show pairs declared in this source file")
+		width     = flag.Int("width", 60, "width of each column of the text output")
+		htmlPath  = flag.String("html", "", "write the listings as HTML to this file instead")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("asmview: ")
+
+	pairs, err := compare.LoadPairs(*pairsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *file != "" {
+		var filtered []compare.Pair
+		for _, p := range pairs {
+			if p.File == *file {
+				filtered = append(filtered, p)
+			}
+		}
+		pairs = filtered
+	}
+	if len(pairs) == 0 {
+		log.Fatal("no pairs to show")
+	}
+
+	listings := make(map[string]*asm.Listing)
+	for _, pkg := range compare.Packages(pairs) {
+		pkgDir := filepath.Join(*dir, filepath.FromSlash(pkg))
+		loops, err := dce.Loops(pkgDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		funcs, err := dce.Disassemble(pkgDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for name, l := range asm.Extract(loops, funcs) {
+			listings[name] = l
+		}
+	}
+
+	views := make([]asm.Pair, len(pairs))
+	for i, p := range pairs {
+		views[i] = asm.Pair{A: p.A, B: p.B, ListA: listings[p.A], ListB: listings[p.B]}
+	}
+
+	if *htmlPath == "" {
+		if err := asm.WriteText(os.Stdout, views, *width); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	f, err := os.Create(*htmlPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := asm.WriteHTML(f, views); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s", *htmlPath)
+}
@@ -0,0 +1,129 @@
+// Command benchgate fails when the relative performance of the declared
+// benchmark variants moves away from a committed baseline.
+//
+// Several of the benchmarks are used to justify coding guidelines, such as
+// passing large structs by reference or pooling buffers. For every pair in
+// variants.json the gate computes the ratio of the median ns/op of B to that
+// of A, both in the baseline and in a fresh run, and exits with a non-zero
+// status if any ratio moved by more than -threshold percent. Comparing ratios
+// rather than absolute times makes the gate robust to running on a faster or
+// slower machine than the baseline.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/benchgate [-baseline results/baseline.json] [-threshold 25]
+//	go run ./cmd/benchgate -update
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+	"github.com/jeromefroe/golang_benchmarks/internal/gate"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+	"github.com/jeromefroe/golang_benchmarks/internal/results"
+)
+
+func main() {
+	var (
+		baselinePath = flag.String("baseline", "results/baseline.json", "path of the baseline result file")
+		pairsPath    = flag.String("pairs", "variants.json", "path of the declared variant pairs")
+		dir          = flag.String("dir", ".", "directory containing the benchmarks")
+		count        = flag.Int("count", 5, "number of samples to take of each benchmark")
+		benchtime    = flag.String("benchtime", "", "value passed to go test -benchtime")
+		threshold    = flag.Float64("threshold", 25, "maximum change of a ratio in percent")
+		in           = flag.String("in", "", "use this result file or go test -bench output instead of running the benchmarks")
+		update       = flag.Bool("update", false, "write the current run to the baseline instead of checking it")
+		file         = flag.String("file", "", "only check pairs declared in this source file")
+		all          = flag.Bool("v", false, "list every pair, not only the ones which failed or flipped")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("benchgate: ")
+
+	pairs, err := compare.LoadPairs(*pairsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *file != "" {
+		var filtered []compare.Pair
+		for _, p := range pairs {
+			if p.File == *file {
+				filtered = append(filtered, p)
+			}
+		}
+		pairs = filtered
+	}
+	if len(pairs) == 0 {
+		log.Fatal("no pairs to check")
+	}
+
+	var current *results.Run
+	if *in != "" {
+		current, err = results.Read(*in)
+	} else {
+		current, err = run(bench.Options{
+			Dir:       *dir,
+			Bench:     bench.Regexp(compare.TopLevel(pairs)),
+			Benchtime: *benchtime,
+			Count:     *count,
+			Output:    os.Stderr,
+		})
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(*baselinePath), 0755); err != nil {
+			log.Fatal(err)
+		}
+		if err := current.Save(*baselinePath); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("wrote baseline to %s", *baselinePath)
+		return
+	}
+
+	baseline, err := results.Load(*baselinePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if baseline.Metadata != nil && current.Metadata != nil {
+		fmt.Printf("baseline: %s\ncurrent:  %s\n\n", baseline.Metadata, current.Metadata)
+	}
+
+	changes := gate.Check(baseline.Set(), current.Set(), pairs, *threshold)
+	if err := gate.WriteDiff(os.Stdout, changes, *all); err != nil {
+		log.Fatal(err)
+	}
+
+	var failed int
+	for _, c := range changes {
+		if c.Failed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\nFAIL: %d of %d ratios moved by more than %g%% or are missing\n", failed, len(changes), *threshold)
+		os.Exit(1)
+	}
+	fmt.Printf("\nok: %d ratios within %g%% of the baseline\n", len(changes), *threshold)
+}
+
+func run(opts bench.Options) (*results.Run, error) {
+	md, err := metadata.Collect()
+	if err != nil {
+		return nil, err
+	}
+	set, err := bench.Run(opts)
+	if err != nil {
+		return nil, err
+	}
+	return results.New(set, md), nil
+}
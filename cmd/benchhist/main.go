@@ -0,0 +1,171 @@
+// Command benchhist keeps a history of benchmark results across Go versions.
+//
+// Runs are saved as JSON documents, one per run, in a local directory. Each
+// document records the metadata of the machine and toolchain along with the
+// ns/op, B/op, allocs/op and custom metrics of every benchmark.
+//
+// Usage, from the root of the repository:
+//
+//	go test -run '^$' -bench . -benchmem | go run ./cmd/benchhist ingest -go go1.23.4
+//	go run ./cmd/benchhist run [-bench regexp] [-count 5]
+//	go run ./cmd/benchhist list
+//	go run ./cmd/benchhist query [-from go1.18] [-to go1.23] [-unit ns/op] BenchmarkInterfaceMethodCall
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+	"github.com/jeromefroe/golang_benchmarks/internal/results"
+)
+
+const usage = `usage: benchhist <command> [flags]
+
+commands:
+  ingest [file]   save go test -bench output read from file or stdin, with -go
+  run             run the benchmarks and save the results
+  list            list the saved runs
+  query regexp    show how the benchmarks matching regexp changed across Go versions
+`
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("benchhist: ")
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	dir := fs.String("store", "results", "directory the runs are stored in")
+
+	var err error
+	switch cmd {
+	case "ingest":
+		goVersion := fs.String("go", "", "Go version the output was generated with, required unless the output has a go: line")
+		fs.Parse(args)
+		err = ingest(results.Store{Dir: *dir}, fs.Arg(0), *goVersion)
+	case "run":
+		opts := bench.Options{}
+		fs.StringVar(&opts.Dir, "dir", ".", "directory containing the benchmarks")
+		fs.StringVar(&opts.Bench, "bench", ".", "regexp of the benchmarks to run")
+		fs.StringVar(&opts.Benchtime, "benchtime", "", "value passed to go test -benchtime")
+		fs.IntVar(&opts.Count, "count", 1, "number of samples to take of each benchmark")
+		fs.Parse(args)
+		opts.Output = os.Stderr
+		err = run(results.Store{Dir: *dir}, opts)
+	case "list":
+		fs.Parse(args)
+		err = list(results.Store{Dir: *dir})
+	case "query":
+		unit := fs.String("unit", "ns/op", "unit to compare, e.g. ns/op, B/op, allocs/op or a custom metric")
+		from := fs.String("from", "", "oldest Go version to include")
+		to := fs.String("to", "", "newest Go version to include")
+		fs.Parse(args)
+		if fs.NArg() != 1 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		err = query(results.Store{Dir: *dir}, fs.Arg(0), *unit, *from, *to)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ingest(store results.Store, path, goVersion string) error {
+	var r io.Reader = os.Stdin
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	set, err := bench.Parse(r)
+	if err != nil {
+		return err
+	}
+	// The output may come from another machine or an older toolchain, so
+	// describe it from its own header rather than from this machine.
+	md := metadata.FromConfig(set.Config)
+	if goVersion != "" {
+		md.GoVersion = goVersion
+	}
+	if md.GoVersion == "" {
+		return fmt.Errorf("the output doesn't say which Go version produced it, set it with -go")
+	}
+	if len(set.Results) > 0 {
+		md.GOMAXPROCS = set.Results[0].Procs
+	}
+	return save(store, set, md)
+}
+
+func run(store results.Store, opts bench.Options) error {
+	md, err := metadata.Collect()
+	if err != nil {
+		return err
+	}
+	set, err := bench.Run(opts)
+	if err != nil {
+		return err
+	}
+	return save(store, set, md)
+}
+
+func save(store results.Store, set *bench.Set, md *metadata.Metadata) error {
+	if len(set.Results) == 0 {
+		return fmt.Errorf("no benchmark results found")
+	}
+	path, err := store.Add(results.New(set, md))
+	if err != nil {
+		return err
+	}
+	log.Printf("saved %d results to %s", len(set.Results), path)
+	return nil
+}
+
+func list(store results.Store) error {
+	runs, err := store.List()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "time\tgo\tbenchmarks\tmachine\n")
+	for _, r := range runs {
+		machine := ""
+		if r.Metadata != nil {
+			machine = r.Metadata.CPUModel
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", r.Time.Format("2006-01-02 15:04"), r.GoVersion(), len(r.Benchmarks), machine)
+	}
+	return tw.Flush()
+}
+
+func query(store results.Store, pattern, unit, from, to string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	runs, err := store.List()
+	if err != nil {
+		return err
+	}
+	h := results.Query(runs, re, unit, from, to)
+	if len(h.Names) == 0 {
+		return fmt.Errorf("no results for %s in %s", pattern, store.Dir)
+	}
+	return h.WriteTable(os.Stdout)
+}
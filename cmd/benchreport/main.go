@@ -0,0 +1,65 @@
+// Command benchreport renders a result file as a single static HTML page with
+// one section of charts per topic.
+//
+// The input is either a run saved by benchhist or benchgate, or raw go test
+// -bench output. The page has no external dependencies. With -escapes, the
+// escape analysis summaries written by cmd/escapes are added as a table.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/benchreport -in results/baseline.json -o report.html
+//	go run ./cmd/escapes -json > escapes.json
+//	go run ./cmd/benchreport -in results/baseline.json -escapes escapes.json
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/escape"
+	"github.com/jeromefroe/golang_benchmarks/internal/report"
+	"github.com/jeromefroe/golang_benchmarks/internal/results"
+)
+
+func main() {
+	var (
+		in      = flag.String("in", "", "result file or go test -bench output to render")
+		out     = flag.String("o", "report.html", "path of the HTML file to write")
+		escapes = flag.String("escapes", "", "escape analysis summaries written by escapes -json")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("benchreport: ")
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	run, err := results.Read(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	page := report.Build(run)
+	if *escapes != "" {
+		summaries, err := escape.Load(*escapes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		page.AttachEscapes(summaries)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := page.Write(f); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s", *out)
+}
@@ -0,0 +1,55 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"io/ioutil"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// cpuSet is a cpu_set_t large enough for 1024 CPUs.
+type cpuSet [16]uint64
+
+// allowedCPUs returns the CPUs the process may run on.
+func allowedCPUs() ([]int, error) {
+	var set cpuSet
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, unsafe.Sizeof(set), uintptr(unsafe.Pointer(&set)))
+	if errno != 0 {
+		return nil, errno
+	}
+	var cpus []int
+	for i := 0; i < len(set)*64; i++ {
+		if set[i/64]&(1<<uint(i%64)) != 0 {
+			cpus = append(cpus, i)
+		}
+	}
+	return cpus, nil
+}
+
+// pin restricts every thread of the process to cpus with sched_setaffinity.
+// Threads and processes started later inherit the affinity of the thread
+// which starts them, so the benchmarks run on cpus too.
+func pin(cpus []int) error {
+	var set cpuSet
+	for _, cpu := range cpus {
+		set[cpu/64] |= 1 << uint(cpu%64)
+	}
+	entries, err := ioutil.ReadDir("/proc/self/task")
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		tid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, uintptr(tid), unsafe.Sizeof(set), uintptr(unsafe.Pointer(&set)))
+		if errno != 0 && errno != syscall.ESRCH {
+			return errno
+		}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "errors"
+
+var errAffinity = errors.New("pinning to CPUs is only supported on Linux")
+
+func allowedCPUs() ([]int, error) { return nil, errAffinity }
+
+func pin(cpus []int) error { return errAffinity }
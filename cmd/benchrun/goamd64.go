@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+	"github.com/jeromefroe/golang_benchmarks/internal/microarch"
+)
+
+func goamd64(args []string) error {
+	fs := flag.NewFlagSet("goamd64", flag.ExitOnError)
+	var (
+		dir       = fs.String("dir", ".", "directory containing the benchmarks")
+		filter    = fs.String("bench", "^Benchmark(Hash|BitTricks|Reduce)", "only run the benchmarks matching this regexp")
+		levels    = fs.String("levels", "v1,v2,v3", "comma separated GOAMD64 levels to compare")
+		benchtime = fs.String("benchtime", "", "value passed to go test -benchtime")
+		count     = fs.Int("count", 5, "number of samples to take of each benchmark at each level")
+	)
+	fs.Parse(args)
+
+	if runtime.GOARCH != "amd64" {
+		return fmt.Errorf("GOAMD64 levels only apply to amd64, not %s", runtime.GOARCH)
+	}
+	want, err := microarch.ParseLevels(*levels)
+	if err != nil {
+		return err
+	}
+	re, err := regexp.Compile(*filter)
+	if err != nil {
+		return err
+	}
+	benchmarks, err := bench.Select(*dir, re)
+	if err != nil {
+		return err
+	}
+	selected := make(map[string][]string)
+	var pkgs []string
+	for _, bm := range benchmarks {
+		if selected[bm.Package] == nil {
+			pkgs = append(pkgs, bm.Package)
+		}
+		selected[bm.Package] = append(selected[bm.Package], bm.Name)
+	}
+
+	md, err := metadata.Collect()
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempDir("", "benchrun")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	var ran []string
+	var sets []*bench.Set
+	for _, level := range want {
+		set := &bench.Set{}
+		supported := true
+		for i, pkg := range pkgs {
+			pkgDir := filepath.Join(*dir, filepath.FromSlash(pkg))
+			bin := filepath.Join(tmp, level+"-"+strconv.Itoa(i)+".test")
+			if err := compileTests(pkgDir, bin, "GOAMD64="+level); err != nil {
+				return err
+			}
+			// A binary built for a level the CPU lacks exits before
+			// running anything, so probe it without running any tests.
+			if out, err := exec.Command(bin, "-test.run", "^$").CombinedOutput(); err != nil {
+				if microarch.Unsupported(out) {
+					fmt.Fprintf(os.Stderr, "skipping GOAMD64=%s: not supported by this CPU\n", level)
+					supported = false
+					break
+				}
+				return fmt.Errorf("%s: %v\n%s", pkg, err, out)
+			}
+			fmt.Fprintf(os.Stderr, "running %d benchmarks in %s at GOAMD64=%s\n", len(selected[pkg]), pkg, level)
+			s, err := runBinary(bin, pkgDir, bench.Regexp(selected[pkg]), *benchtime, *count)
+			if err != nil {
+				return err
+			}
+			set.Results = append(set.Results, s.Results...)
+		}
+		if supported {
+			ran = append(ran, level)
+			sets = append(sets, set)
+		}
+	}
+
+	if len(ran) == 0 {
+		return fmt.Errorf("none of the GOAMD64 levels %s are supported by this CPU", *levels)
+	}
+
+	fmt.Printf("%s\n\n", md)
+	return microarch.WriteTable(os.Stdout, ran, microarch.Rows(sets))
+}
@@ -0,0 +1,71 @@
+// Command benchrun runs the benchmarks in special modes which go test doesn't
+// support on its own.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/benchrun procs [-max 8] [-bench regexp] [-o run.json]
+//	go run ./cmd/benchrun profile [-bench regexp] [-o profiles] [-top 10]
+//	go run ./cmd/benchrun stable [-bench regexp] [-cpus 3] [-cv 0.02] [-budget 1m]
+//	go run ./cmd/benchrun goamd64 [-bench regexp] [-levels v1,v2,v3] [-count 5]
+//
+// The procs mode reruns every benchmark which uses b.RunParallel at
+// GOMAXPROCS 1, 2, 4, ... up to -max and reports the throughput at each
+// proc count, the speedup at -max and the knee of the scaling curve, the
+// proc count after which adding procs stops improving throughput.
+//
+// The profile mode runs each benchmark on its own with a CPU and a heap
+// profile and prints its results followed by the functions with the most
+// samples in each profile. The profiles and summaries are kept in -o.
+//
+// The stable mode pins the benchmarks to a fixed set of CPUs, discards
+// warmup runs and then samples each benchmark until the coefficient of
+// variation of its ns/op falls below -cv. Benchmarks which don't settle
+// within -max runs or -budget are flagged as unstable. Pinning to fewer CPUs
+// also lowers GOMAXPROCS for the benchmarks.
+//
+// The goamd64 mode builds the benchmarks once for each GOAMD64 level in
+// -levels, skips the levels the CPU can't execute and reports the median
+// ns/op at each level along with its speedup over the lowest one.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+const usage = `usage: benchrun <mode> [flags]
+
+modes:
+  procs     sweep the parallel benchmarks over GOMAXPROCS
+  profile   profile each benchmark and summarize the hottest functions
+  stable    pin, warm up and sample each benchmark until its results settle
+  goamd64   compare the benchmarks built for each GOAMD64 level
+`
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("benchrun: ")
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch mode, args := os.Args[1], os.Args[2:]; mode {
+	case "procs":
+		err = procs(args)
+	case "profile":
+		err = profileMode(args)
+	case "stable":
+		err = stable(args)
+	case "goamd64":
+		err = goamd64(args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
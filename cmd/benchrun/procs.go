@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+	"github.com/jeromefroe/golang_benchmarks/internal/results"
+	"github.com/jeromefroe/golang_benchmarks/internal/scaling"
+)
+
+func procs(args []string) error {
+	fs := flag.NewFlagSet("procs", flag.ExitOnError)
+	var (
+		dir       = fs.String("dir", ".", "directory containing the benchmarks")
+		max       = fs.Int("max", runtime.NumCPU(), "largest GOMAXPROCS to run at")
+		filter    = fs.String("bench", ".", "only sweep the parallel benchmarks matching this regexp")
+		benchtime = fs.String("benchtime", "", "value passed to go test -benchtime")
+		count     = fs.Int("count", 3, "number of samples to take at each proc count")
+		minGain   = fs.Float64("min-gain", 0.1, "minimum relative throughput gain for an extra step of procs to count as scaling")
+		out       = fs.String("o", "", "also save the run to this result file")
+	)
+	fs.Parse(args)
+
+	procs, err := scaling.Procs(*max)
+	if err != nil {
+		return err
+	}
+	re, err := regexp.Compile(*filter)
+	if err != nil {
+		return err
+	}
+	files, err := bench.Discover(*dir)
+	if err != nil {
+		return err
+	}
+	var names, pkgs []string
+	for _, f := range files {
+		matched := false
+		for _, name := range f.Parallel {
+			if re.MatchString(name) {
+				names = append(names, name)
+				matched = true
+			}
+		}
+		if matched && (len(pkgs) == 0 || pkgs[len(pkgs)-1] != f.Package()) {
+			pkgs = append(pkgs, f.Package())
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no parallel benchmarks match %s", *filter)
+	}
+
+	md, err := metadata.Collect()
+	if err != nil {
+		return err
+	}
+	set, err := bench.Run(bench.Options{
+		Dir:       *dir,
+		Packages:  pkgs,
+		Bench:     bench.Regexp(names),
+		Benchtime: *benchtime,
+		Count:     *count,
+		CPU:       scaling.CPUFlag(procs),
+		Output:    os.Stderr,
+	})
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		if err := results.New(set, md).Save(*out); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%s\n\n", md)
+	return scaling.WriteTable(os.Stdout, scaling.Curves(set), *minGain)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/profile"
+)
+
+func profileMode(args []string) error {
+	fs := flag.NewFlagSet("profile", flag.ExitOnError)
+	var (
+		dir       = fs.String("dir", ".", "directory containing the benchmarks")
+		filter    = fs.String("bench", ".", "only profile the benchmarks matching this regexp")
+		benchtime = fs.String("benchtime", "", "value passed to go test -benchtime")
+		outDir    = fs.String("o", "profiles", "directory to write the profiles and summaries to")
+		top       = fs.Int("top", 10, "number of functions to list in each summary")
+		memType   = fs.String("mem", "alloc_space", "sample type of the heap profile to summarize")
+	)
+	fs.Parse(args)
+
+	re, err := regexp.Compile(*filter)
+	if err != nil {
+		return err
+	}
+	selected, err := bench.Select(*dir, re)
+	if err != nil {
+		return err
+	}
+
+	out, err := filepath.Abs(*outDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+
+	for _, bm := range selected {
+		name := bm.Name
+		var summary bytes.Buffer
+		if err := profileBenchmark(&summary, *dir, bm.Package, name, *benchtime, out, *top, *memType); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		os.Stdout.Write(summary.Bytes())
+		fmt.Println()
+		path := filepath.Join(out, name+".txt")
+		if err := ioutil.WriteFile(path, summary.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profileBenchmark runs a single benchmark with CPU and heap profiling and
+// writes its results followed by the top functions of each profile to w.
+// The profiles are kept in out for further inspection with go tool pprof.
+func profileBenchmark(w io.Writer, dir, pkg, name, benchtime, out string, top int, memType string) error {
+	cpuPath := filepath.Join(out, name+".cpu.pprof")
+	memPath := filepath.Join(out, name+".mem.pprof")
+	raw, err := bench.RunRaw(bench.Options{
+		Dir:       dir,
+		Packages:  []string{pkg},
+		Bench:     bench.Regexp([]string{name}),
+		Benchtime: benchtime,
+		Args: []string{
+			"-cpuprofile", cpuPath,
+			"-memprofile", memPath,
+			"-o", filepath.Join(out, "bench.test"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer os.Remove(filepath.Join(out, "bench.test"))
+
+	s := bufio.NewScanner(bytes.NewReader(raw))
+	for s.Scan() {
+		if strings.HasPrefix(s.Text(), "Benchmark") {
+			fmt.Fprintln(w, s.Text())
+		}
+	}
+
+	for _, p := range []struct {
+		path, typ string
+	}{
+		{cpuPath, "cpu"},
+		{memPath, memType},
+	} {
+		prof, err := profile.Read(p.path)
+		if err != nil {
+			return err
+		}
+		i := prof.Index(p.typ)
+		if i < 0 {
+			return fmt.Errorf("%s has no %s samples", p.path, p.typ)
+		}
+		entries, total := prof.Top(i, top)
+		fmt.Fprintln(w)
+		if err := profile.WriteTop(w, prof.SampleTypes[i], entries, total); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+	"github.com/jeromefroe/golang_benchmarks/internal/stability"
+)
+
+func stable(args []string) error {
+	fs := flag.NewFlagSet("stable", flag.ExitOnError)
+	opts := stability.DefaultOptions
+	var (
+		dir       = fs.String("dir", ".", "directory containing the benchmarks")
+		filter    = fs.String("bench", ".", "only run the benchmarks matching this regexp")
+		benchtime = fs.String("benchtime", "", "value passed to go test -benchtime")
+		cpuList   = fs.String("cpus", "", "CPUs to pin the benchmarks to, e.g. 2 or 2-3,6; defaults to the last CPU")
+	)
+	fs.IntVar(&opts.Warmup, "warmup", opts.Warmup, "number of runs of each benchmark to discard")
+	fs.Float64Var(&opts.Target, "cv", opts.Target, "coefficient of variation below which a benchmark is stable")
+	fs.IntVar(&opts.MinRuns, "min", opts.MinRuns, "minimum number of samples of each benchmark")
+	fs.IntVar(&opts.MaxRuns, "max", opts.MaxRuns, "maximum number of samples of each benchmark")
+	fs.DurationVar(&opts.Budget, "budget", opts.Budget, "maximum time to spend sampling each benchmark")
+	fs.Parse(args)
+
+	re, err := regexp.Compile(*filter)
+	if err != nil {
+		return err
+	}
+	selected, err := bench.Select(*dir, re)
+	if err != nil {
+		return err
+	}
+
+	cpus, err := parseCPUs(*cpuList)
+	if err != nil {
+		return err
+	}
+	if err := pin(cpus); err != nil {
+		fmt.Fprintf(os.Stderr, "not pinning: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "pinned to CPUs %v\n", cpus)
+	}
+
+	md, err := metadata.Collect()
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempDir("", "benchrun")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	bins := make(map[string]string)
+	var all []*stability.Result
+	for _, bm := range selected {
+		pkgDir := filepath.Join(*dir, filepath.FromSlash(bm.Package))
+		bin, ok := bins[bm.Package]
+		if !ok {
+			bin = filepath.Join(tmp, fmt.Sprintf("bench%d.test", len(bins)))
+			if err := compileTests(pkgDir, bin); err != nil {
+				return err
+			}
+			bins[bm.Package] = bin
+		}
+		results, err := stability.Sample(func() (*bench.Set, error) {
+			return runBinary(bin, pkgDir, bench.Regexp([]string{bm.Name}), *benchtime, 1)
+		}, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %v", bm.Name, err)
+		}
+		for _, r := range results {
+			fmt.Fprintf(os.Stderr, "%s: %d runs, cv %.1f%%, %s\n", r.Name, len(r.Samples), 100*r.CV(), r.Status())
+		}
+		all = append(all, results...)
+	}
+
+	fmt.Printf("%s\n\n", md)
+	return stability.WriteTable(os.Stdout, all)
+}
+
+// parseCPUs parses a CPU list such as 0-3,6. An empty list selects the last
+// CPU the process is allowed to run on, which is usually less busy handling
+// interrupts than CPU 0.
+func parseCPUs(list string) ([]int, error) {
+	if list == "" {
+		allowed, err := allowedCPUs()
+		if err != nil || len(allowed) == 0 {
+			return nil, nil
+		}
+		return allowed[len(allowed)-1:], nil
+	}
+	var cpus []int
+	for _, part := range strings.Split(list, ",") {
+		lo, hi := part, part
+		if i := strings.IndexByte(part, '-'); i >= 0 {
+			lo, hi = part[:i], part[i+1:]
+		}
+		from, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list %q", list)
+		}
+		to, err := strconv.Atoi(hi)
+		if err != nil || to < from {
+			return nil, fmt.Errorf("invalid CPU list %q", list)
+		}
+		for cpu := from; cpu <= to; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// compileTests compiles the test binary for the package in dir to bin once,
+// so that repeated runs don't pay for go test rebuilding it.
+func compileTests(dir, bin string, env ...string) error {
+	cmd := exec.Command("go", "test", "-c", "-o", bin, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("compiling test binary for %s: %v", dir, err)
+	}
+	return nil
+}
+
+// runBinary runs the benchmarks matching pattern count times with the
+// compiled test binary.
+func runBinary(bin, dir, pattern, benchtime string, count int) (*bench.Set, error) {
+	args := []string{"-test.run", "^$", "-test.bench", pattern, "-test.benchmem", "-test.count", strconv.Itoa(count)}
+	if benchtime != "" {
+		args = append(args, "-test.benchtime", benchtime)
+	}
+	var out bytes.Buffer
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, out.Bytes())
+	}
+	return bench.Parse(&out)
+}
@@ -0,0 +1,103 @@
+// Command catalog lists the benchmarks in the repository, filtered by tag, and
+// prints or runs the go test command which executes exactly that selection.
+//
+// The catalog is read from catalog.json at the root of the repository, which
+// gives each benchmark a description and tags such as concurrency,
+// allocation, hashing, compiler-optimization or third-party. With several
+// tags only the benchmarks declaring all of them are selected.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/catalog [-tag concurrency,allocation] [-run] [-cmd]
+//	go run ./cmd/catalog -tags
+//	go run ./cmd/catalog -check
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/catalog"
+)
+
+func main() {
+	var (
+		catalogPath = flag.String("catalog", "catalog.json", "path of the benchmark catalog")
+		dir         = flag.String("dir", ".", "directory containing the benchmarks")
+		tag         = flag.String("tag", "", "comma separated tags the selected benchmarks must all declare")
+		listTags    = flag.Bool("tags", false, "list the known tags and how many benchmarks declare each")
+		check       = flag.Bool("check", false, "check that the catalog lists every benchmark exactly once")
+		cmdOnly     = flag.Bool("cmd", false, "only print the go test command for the selection")
+		run         = flag.Bool("run", false, "run the selected benchmarks")
+		benchtime   = flag.String("benchtime", "", "value passed to go test -benchtime with -run")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("catalog: ")
+
+	entries, err := catalog.Load(*catalogPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch {
+	case *check:
+		files, err := bench.Discover(*dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		problems := catalog.Check(entries, files)
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		fmt.Printf("%d benchmarks catalogued\n", len(entries))
+		return
+	case *listTags:
+		counts := catalog.Counts(entries)
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "tag\tbenchmarks")
+		for _, t := range catalog.Tags {
+			fmt.Fprintf(w, "%s\t%d\n", t, counts[t])
+		}
+		w.Flush()
+		return
+	}
+
+	selected := catalog.Filter(entries, catalog.ParseTags(*tag))
+	if len(selected) == 0 {
+		log.Fatalf("no benchmarks are tagged %s", *tag)
+	}
+
+	if *run {
+		pattern := bench.Regexp(catalog.Names(selected))
+		opts := bench.Options{
+			Dir:       *dir,
+			Packages:  catalog.Packages(selected),
+			Bench:     pattern,
+			Benchtime: *benchtime,
+			Output:    os.Stdout,
+		}
+		if _, err := bench.RunRaw(opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if !*cmdOnly {
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "benchmark\tfile\ttags\tdescription")
+		for _, e := range selected {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Benchmark, e.File, strings.Join(e.Tags, ","), e.Description)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	fmt.Println(catalog.Command(selected))
+}
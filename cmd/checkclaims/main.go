@@ -0,0 +1,117 @@
+// Command checkclaims checks whether the conclusions drawn in the README still
+// hold on the current Go version and machine.
+//
+// The claims are read from the claims.json file next to the benchmarks of
+// each package, e.g. sync/claims.json. Each claim names two benchmarks, A and B, and either states that A is faster
+// than B by at least a given ratio or that the two are similar to within a
+// given ratio. Both sides of every claim are run several times and each claim
+// is reported as holding, weakened or flipped.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/checkclaims [-count 5] [-file sync/defer_test.go] [-in output.txt]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/claims"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+)
+
+func main() {
+	var (
+		dir       = flag.String("dir", ".", "directory containing the benchmarks")
+		count     = flag.Int("count", 5, "number of samples to take of each benchmark")
+		benchtime = flag.String("benchtime", "", "value passed to go test -benchtime")
+		file      = flag.String("file", "", "only check claims about this source file")
+		in        = flag.String("in", "", "read go test -bench output from this file instead of running the benchmarks")
+		alpha     = flag.Float64("alpha", compare.DefaultOptions.Alpha, "significance level of the Mann-Whitney U test")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("checkclaims: ")
+
+	cs, err := claims.LoadAll(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *file != "" {
+		var filtered []claims.Claim
+		for _, c := range cs {
+			if c.File == *file {
+				filtered = append(filtered, c)
+			}
+		}
+		cs = filtered
+	}
+	if len(cs) == 0 {
+		log.Fatal("no claims to check")
+	}
+
+	var set *bench.Set
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			log.Fatal(err)
+		}
+		set, err = bench.Parse(f)
+		f.Close()
+	} else {
+		pairs := make([]compare.Pair, len(cs))
+		for i, c := range cs {
+			pairs[i] = c.Pair()
+		}
+		set, err = bench.Run(bench.Options{
+			Dir:       *dir,
+			Packages:  compare.Packages(pairs),
+			Bench:     bench.Regexp(compare.TopLevel(pairs)),
+			Benchtime: *benchtime,
+			Count:     *count,
+		})
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := compare.DefaultOptions
+	opts.Alpha = *alpha
+	outcomes := claims.Check(set, cs, opts)
+
+	if md, err := metadata.Collect(); err == nil && *in == "" {
+		fmt.Printf("%s\n\n", md)
+	}
+
+	counts := make(map[claims.Status]int)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "status\tclaim\texpected\tobserved\tp\tdescription\n")
+	for _, o := range outcomes {
+		counts[o.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.3f\t%s\n",
+			o.Status, o.ID, expected(o.Claim), observed(o), o.Comparison.P, o.Text)
+	}
+	tw.Flush()
+
+	fmt.Printf("\n%d claims: %d hold, %d weakened, %d flipped, %d missing\n",
+		len(outcomes), counts[claims.Holds], counts[claims.Weakened], counts[claims.Flipped], counts[claims.Missing])
+}
+
+func expected(c claims.Claim) string {
+	if c.Relation == claims.Similar {
+		return fmt.Sprintf("within %.2fx", c.Ratio)
+	}
+	return fmt.Sprintf(">= %.2fx", c.Ratio)
+}
+
+func observed(o claims.Outcome) string {
+	if o.Status == claims.Missing {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", o.Observed)
+}
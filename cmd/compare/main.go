@@ -0,0 +1,89 @@
+// Command compare runs each declared pair of benchmark variants several times
+// and reports whether the difference between them is statistically
+// significant.
+//
+// The pairs are read from variants.json at the root of the repository. For
+// each pair the median ns/op and its confidence interval are reported along
+// with the p-value of a Mann-Whitney U test, for example:
+//
+//	BenchmarkMutexUnlock is 72.1% faster than BenchmarkMutexDeferUnlock (p=0.000)
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/compare [-count 10] [-file sync/defer_test.go] [-in output.txt]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+)
+
+func main() {
+	var (
+		pairsPath  = flag.String("pairs", "variants.json", "path of the declared variant pairs")
+		dir        = flag.String("dir", ".", "directory containing the benchmarks")
+		count      = flag.Int("count", 10, "number of samples to take of each benchmark")
+		benchtime  = flag.String("benchtime", "", "value passed to go test -benchtime")
+		file       = flag.String("file", "", "only compare pairs declared in this source file")
+		in         = flag.String("in", "", "read go test -bench output from this file instead of running the benchmarks")
+		confidence = flag.Float64("confidence", compare.DefaultOptions.Confidence, "confidence level of the median intervals")
+		alpha      = flag.Float64("alpha", compare.DefaultOptions.Alpha, "significance level of the Mann-Whitney U test")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("compare: ")
+
+	pairs, err := compare.LoadPairs(*pairsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *file != "" {
+		var filtered []compare.Pair
+		for _, p := range pairs {
+			if p.File == *file {
+				filtered = append(filtered, p)
+			}
+		}
+		pairs = filtered
+	}
+	if len(pairs) == 0 {
+		log.Fatal("no pairs to compare")
+	}
+
+	var set *bench.Set
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			log.Fatal(err)
+		}
+		set, err = bench.Parse(f)
+		f.Close()
+	} else {
+		set, err = bench.Run(bench.Options{
+			Dir:       *dir,
+			Packages:  compare.Packages(pairs),
+			Bench:     bench.Regexp(compare.TopLevel(pairs)),
+			Benchtime: *benchtime,
+			Count:     *count,
+		})
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if md, err := metadata.Collect(); err == nil && *in == "" {
+		fmt.Printf("%s\n\n", md)
+	}
+
+	opts := compare.Options{Confidence: *confidence, Alpha: *alpha}
+	cs := compare.Compare(set, pairs, opts)
+	if err := compare.WriteTable(os.Stdout, cs, opts.Confidence); err != nil {
+		log.Fatal(err)
+	}
+}
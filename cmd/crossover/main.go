@@ -0,0 +1,96 @@
+// Command crossover shows which of several implementations of an operation
+// is fastest at each input size, and the sizes at which the winner changes.
+//
+// By default it runs BenchmarkHashSize, which hashes inputs from 4 bytes to
+// 1M with every hash in the hashing package, and ranks the hashes by
+// throughput at each size. It can also read the results of an earlier run,
+// either saved by benchhist or benchgate or as raw go test -bench output.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/crossover [-benchtime 100ms] [-count 3] [-top 3]
+//	go run ./cmd/crossover -in results/baseline.json
+//	go run ./cmd/crossover -pkg maps -bench '^BenchmarkMapString$' \
+//		-pattern '^Benchmark(?P<series>MapString)/keylen=(?P<size>\d+[KM]?)$'
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/crossover"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+	"github.com/jeromefroe/golang_benchmarks/internal/results"
+)
+
+func main() {
+	var (
+		in        = flag.String("in", "", "result file or go test -bench output to read instead of running the benchmarks")
+		dir       = flag.String("dir", ".", "directory containing the benchmarks")
+		pkg       = flag.String("pkg", "hashing", "package of the benchmarks, relative to -dir")
+		filter    = flag.String("bench", "^BenchmarkHashSize$", "regexp of the benchmarks to run")
+		pattern   = flag.String("pattern", `^BenchmarkHashSize/(?P<series>\w+)/size=(?P<size>\d+[KM]?)$`, "regexp extracting the series and size of each result")
+		benchtime = flag.String("benchtime", "", "value passed to go test -benchtime")
+		count     = flag.Int("count", 1, "number of samples to take of each benchmark")
+		top       = flag.Int("top", 3, "number of fastest implementations to show at each size")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("crossover: ")
+
+	re, err := regexp.Compile(*pattern)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var set *bench.Set
+	if *in != "" {
+		run, err := results.Read(*in)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if run.Metadata != nil {
+			fmt.Printf("%s\n\n", run.Metadata)
+		}
+		set = run.Set()
+	} else {
+		md, err := metadata.Collect()
+		if err != nil {
+			log.Fatal(err)
+		}
+		set, err = bench.Run(bench.Options{
+			Dir:       *dir,
+			Packages:  []string{*pkg},
+			Bench:     *filter,
+			Benchtime: *benchtime,
+			Count:     *count,
+			Output:    os.Stderr,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s\n\n", md)
+	}
+
+	rows, err := crossover.Build(set, re)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(rows) == 0 {
+		log.Fatalf("no results match %s", *pattern)
+	}
+	if err := crossover.WriteTable(os.Stdout, rows, *top); err != nil {
+		log.Fatal(err)
+	}
+	if changes := crossover.Crossovers(rows); len(changes) > 0 {
+		fmt.Println()
+		fmt.Println("crossovers:")
+		for _, c := range changes {
+			fmt.Printf("  %s\n", c)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Command dceguard checks that the bodies of the benchmark loops survive
+// compilation.
+//
+// It compiles the test binary of each benchmark package, disassembles every
+// benchmark and the closures it declares with go tool objdump, and reports
+// each loop in a benchmark whose body has no instructions left, which usually
+// means the compiler proved the work unused and deleted it. Such benchmarks
+// should assign their results to one of the fields of harness.Sink. It exits
+// with status 1 if any loop is empty.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/dceguard [-dir .] [-v]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/dce"
+)
+
+func main() {
+	var (
+		dir     = flag.String("dir", ".", "directory containing the benchmark packages")
+		verbose = flag.Bool("v", false, "list every loop, not only the empty ones")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("dceguard: ")
+
+	pkgs, err := bench.Packages(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var findings []dce.Finding
+	for _, pkg := range pkgs {
+		pkgDir := filepath.Join(*dir, filepath.FromSlash(pkg))
+		loops, err := dce.Loops(pkgDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		funcs, err := dce.Disassemble(pkgDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, f := range dce.Check(loops, funcs) {
+			f.File = path.Join(pkg, f.File)
+			findings = append(findings, f)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "loop\tbenchmark\tbody instructions\tstatus")
+	empty := 0
+	for _, f := range findings {
+		if f.Status == dce.Empty {
+			empty++
+		} else if !*verbose {
+			continue
+		}
+		fmt.Fprintf(w, "%s:%d\t%s\t%d\t%s\n", f.File, f.Line, f.Benchmark, f.Insts, f.Status)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d loops checked, %d empty\n", len(findings), empty)
+	if empty > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+// Command escapes summarizes, for each benchmark, what the compiler moves to
+// the heap and which calls it inlines.
+//
+// It builds the test binary of each benchmark package with -gcflags=-m=2, attributes each diagnostic to
+// the function it is in and reports it under every benchmark which is or calls
+// that function, so that the escape analysis of helpers such as
+// unsafeStrToByte or (*ChannelBufferPool).Get shows up next to the benchmarks
+// which use them. With -json the summaries are written as JSON, which
+// benchreport can attach to the result report.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/escapes [-bench Allocate] [-json > escapes.json]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/escape"
+)
+
+func main() {
+	var (
+		dir     = flag.String("dir", ".", "directory containing the benchmark packages")
+		pattern = flag.String("bench", ".", "only summarize benchmarks matching this regexp")
+		asJSON  = flag.Bool("json", false, "write the summaries as JSON")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("escapes: ")
+
+	re, err := regexp.Compile(*pattern)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pkgs, err := bench.Packages(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var summaries []escape.Summary
+	for _, pkg := range pkgs {
+		pkgDir := filepath.Join(*dir, filepath.FromSlash(pkg))
+		diags, err := escape.Build(pkgDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		all, err := escape.Summarize(pkgDir, diags)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range all {
+			if re.MatchString(s.Benchmark) {
+				s.File = path.Join(pkg, s.File)
+				summaries = append(summaries, s)
+			}
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summaries); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	for _, s := range summaries {
+		fmt.Printf("%s (%s:%d)\n", s.Benchmark, s.File, s.Line)
+		if len(s.Helpers) > 0 {
+			fmt.Printf("\thelpers:     %s\n", strings.Join(s.Helpers, ", "))
+		}
+		for _, d := range s.Heap {
+			fmt.Printf("\theap:        %s (%s, %s:%d)\n", d.Subject, d.Kind, d.Func, d.Line)
+		}
+		if len(s.Inlined) > 0 {
+			fmt.Printf("\tinlined:     %s\n", strings.Join(s.Inlined, ", "))
+		}
+		for _, d := range s.NotInlined {
+			fmt.Printf("\tnot inlined: %s (%s)\n", d.Subject, d.Reason)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Command readme-gen runs the benchmarks in each source file and rewrites the
+// result tables in README.md.
+//
+// Each table in the README is wrapped in a pair of markers naming the file
+// whose benchmarks it shows, relative to the root of the repository:
+//
+//	<!-- readme-gen:begin alloc/append_test.go -->
+//	...
+//	<!-- readme-gen:end -->
+//
+// Everything between the markers is replaced with a freshly generated table
+// and a line describing the machine and toolchain used, see the metadata
+// package. Everything outside of the markers is left untouched.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./cmd/readme-gen [-benchtime 1s] [-files alloc/append_test.go,...] [-n]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+)
+
+var blockRe = regexp.MustCompile(`(?s)(<!-- readme-gen:begin (\S+) -->\n)(.*?)(<!-- readme-gen:end -->)`)
+
+func main() {
+	var (
+		readme    = flag.String("readme", "README.md", "path of the README to rewrite")
+		dir       = flag.String("dir", ".", "directory containing the benchmarks")
+		benchtime = flag.String("benchtime", "", "value passed to go test -benchtime")
+		only      = flag.String("files", "", "comma separated list of source files to regenerate, defaults to all")
+		dryRun    = flag.Bool("n", false, "print the new README to stdout instead of rewriting it")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("readme-gen: ")
+
+	src, err := ioutil.ReadFile(*readme)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	files, err := bench.Discover(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	byName := make(map[string]bench.File, len(files))
+	for _, f := range files {
+		byName[f.Name] = f
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(*only, ",") {
+		if name != "" {
+			selected[name] = true
+		}
+	}
+
+	md, err := metadata.Collect()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var failed error
+	out := blockRe.ReplaceAllFunc(src, func(block []byte) []byte {
+		m := blockRe.FindSubmatch(block)
+		file := string(m[2])
+		if failed != nil || (len(selected) > 0 && !selected[file]) {
+			return block
+		}
+		f, ok := byName[file]
+		if !ok {
+			failed = fmt.Errorf("%s: no benchmarks found in %s", *readme, file)
+			return block
+		}
+
+		log.Printf("running %d benchmarks in %s", len(f.Benchmarks), file)
+		set, err := bench.Run(bench.Options{
+			Dir:       *dir,
+			Packages:  []string{f.Package()},
+			Bench:     bench.Regexp(f.Benchmarks),
+			Benchtime: *benchtime,
+		})
+		if err != nil {
+			failed = err
+			return block
+		}
+
+		var buf bytes.Buffer
+		buf.Write(m[1])
+		writeTable(&buf, set.Results)
+		buf.WriteString("\n")
+		fmt.Fprintf(&buf, "Generated using %s\n", md)
+		buf.Write(m[4])
+		return buf.Bytes()
+	})
+	if failed != nil {
+		log.Fatal(failed)
+	}
+
+	if *dryRun {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := ioutil.WriteFile(*readme, out, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeTable writes results as a markdown table with aligned columns.
+func writeTable(buf *bytes.Buffer, results []*bench.Result) {
+	rows := make([][]string, len(results))
+	var widths [5]int
+	for i, r := range results {
+		rows[i] = []string{
+			r.Name,
+			strconv.Itoa(r.Iterations),
+			bench.FormatValue(r.NsPerOp) + " ns/op",
+			strconv.FormatInt(r.BytesPerOp, 10) + " B/op",
+			strconv.FormatInt(r.AllocsPerOp, 10) + " allocs/op",
+		}
+		for j, cell := range rows[i] {
+			if len(cell) > widths[j] {
+				widths[j] = len(cell)
+			}
+		}
+	}
+
+	buf.WriteString("Benchmark Name|Iterations|Per-Iteration|Bytes Allocated per Operation|Allocations per Operation\n")
+	buf.WriteString("----|----|----|----|----\n")
+	for _, row := range rows {
+		for j, cell := range row {
+			if j > 0 {
+				buf.WriteString(" | ")
+			}
+			if j == 0 {
+				fmt.Fprintf(buf, "%-*s", widths[j], cell)
+			} else {
+				fmt.Fprintf(buf, "%*s", widths[j], cell)
+			}
+		}
+		buf.WriteString("\n")
+	}
+}
@@ -1,41 +1,43 @@
-package main
+package compiler
 
-import "testing"
+import (
+	"testing"
 
-var btGlobal int
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+)
 
 func BenchmarkBitTricksModPowerOfTwo(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		btGlobal = i % 256
+		harness.Sink.Int = i % 256
 	}
 }
 
 func BenchmarkBitTricksModNonPowerOfTwo(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		btGlobal = i % 257
+		harness.Sink.Int = i % 257
 	}
 }
 
 func BenchmarkBitTricksAnd(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		btGlobal = i & 256
+		harness.Sink.Int = i & 256
 	}
 }
 
 func BenchmarkBitTricksDividePowerOfTwo(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		btGlobal = i / 256
+		harness.Sink.Int = i / 256
 	}
 }
 
 func BenchmarkBitTricksDivideNonPowerOfTwo(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		btGlobal = i / 257
+		harness.Sink.Int = i / 257
 	}
 }
 
 func BenchmarkBitTricksShift(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		btGlobal = i >> 8
+		harness.Sink.Int = i >> 8
 	}
 }
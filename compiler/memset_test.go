@@ -0,0 +1,36 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+)
+
+// memsetLens are the lengths of the slices cleared.
+var memsetLens = harness.Axis{Name: "len", Values: []int{1 << 10, 16 << 10, 128 << 10}}
+
+func BenchmarkSliceClearZero(b *testing.B) {
+	harness.Sweep(b, []harness.Axis{memsetLens}, func(p harness.Params) func(b *testing.B) {
+		data := make([]byte, p.Int("len"))
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range data {
+					data[j] = 0
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkSliceClearNonZero(b *testing.B) {
+	harness.Sweep(b, []harness.Axis{memsetLens}, func(p harness.Params) func(b *testing.B) {
+		data := make([]byte, p.Int("len"))
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range data {
+					data[j] = 1
+				}
+			}
+		}
+	})
+}
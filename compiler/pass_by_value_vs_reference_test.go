@@ -1,4 +1,4 @@
-package main
+package compiler
 
 import "testing"
 
@@ -16,62 +16,78 @@ type EightWords struct {
 	b FourWords
 }
 
+// The functions below do nothing with their argument, they only exist to be
+// called. They must not be inlined, otherwise the compiler deletes the calls
+// and there is nothing left to benchmark.
+
+//go:noinline
+func oneWordByReference(s *OneWord) {}
+
+//go:noinline
+func oneWordByValue(s OneWord) {}
+
+//go:noinline
+func fourWordsByReference(s *FourWords) {}
+
+//go:noinline
+func fourWordsByValue(s FourWords) {}
+
+//go:noinline
+func eightWordsByReference(s *EightWords) {}
+
+//go:noinline
+func eightWordsByValue(s EightWords) {}
+
 func BenchmarkPassByReferenceOneWord(b *testing.B) {
-	f := func(s *OneWord) {}
 	s := OneWord(0)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		f(&s)
+		oneWordByReference(&s)
 	}
 }
 
 func BenchmarkPassByValueOneWord(b *testing.B) {
-	f := func(s OneWord) {}
 	s := OneWord(0)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		f(s)
+		oneWordByValue(s)
 	}
 }
 
 func BenchmarkPassByReferenceFourWords(b *testing.B) {
-	f := func(s *FourWords) {}
 	s := FourWords{}
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		f(&s)
+		fourWordsByReference(&s)
 	}
 }
 
 func BenchmarkPassByValueFourWords(b *testing.B) {
-	f := func(s FourWords) {}
 	s := FourWords{}
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		f(s)
+		fourWordsByValue(s)
 	}
 }
 
 func BenchmarkPassByReferenceEightWords(b *testing.B) {
-	f := func(s *EightWords) {}
 	s := EightWords{}
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		f(&s)
+		eightWordsByReference(&s)
 	}
 }
 
 func BenchmarkPassByValueEightWords(b *testing.B) {
-	f := func(s EightWords) {}
 	s := EightWords{}
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		f(s)
+		eightWordsByValue(s)
 	}
 }
@@ -1,21 +1,21 @@
-package main
+package compiler
 
 import (
 	"math/rand"
 	"testing"
 	"time"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
 )
 
 const rangeBound int32 = 1357
 
-var globalVar int32
-
 func BenchmarkStandardBoundedRandomNumber(b *testing.B) {
 	s := rand.NewSource(time.Now().UnixNano())
 	r := rand.New(s)
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
-		globalVar = r.Int31n(rangeBound)
+		harness.Sink.Int32 = r.Int31n(rangeBound)
 	}
 }
 
@@ -26,7 +26,7 @@ func BenchmarkBiasedFastBoundedRandomNumber(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		random := int64(r.Int31())
 		multiResult := random * int64(rangeBound)
-		globalVar = int32(multiResult >> 32)
+		harness.Sink.Int32 = int32(multiResult >> 32)
 	}
 }
 
@@ -46,6 +46,6 @@ func BenchmarkUnbiasedFastBoundedRandomNumber(b *testing.B) {
 				leftover = int32(multiResult)
 			}
 		}
-		globalVar = int32(multiResult >> 32)
+		harness.Sink.Int32 = int32(multiResult >> 32)
 	}
 }
@@ -1,8 +1,10 @@
-package main
+package compiler
 
-import "testing"
+import (
+	"testing"
 
-var rGlobal uint32
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+)
 
 func BenchmarkReduceModuloPowerOfTwo(b *testing.B) {
 	var (
@@ -10,7 +12,7 @@ func BenchmarkReduceModuloPowerOfTwo(b *testing.B) {
 		n uint32 = 256
 	)
 	for i := 0; i < b.N; i++ {
-		rGlobal = u % n
+		harness.Sink.Uint32 = u % n
 		u++
 	}
 }
@@ -21,7 +23,7 @@ func BenchmarkReduceModuloNonPowerOfTwo(b *testing.B) {
 		n uint32 = 257
 	)
 	for i := 0; i < b.N; i++ {
-		rGlobal = u % n
+		harness.Sink.Uint32 = u % n
 		u++
 	}
 }
@@ -32,7 +34,7 @@ func BenchmarkReduceAlternativePowerOfTwo(b *testing.B) {
 		n uint32 = 256
 	)
 	for i := 0; i < b.N; i++ {
-		rGlobal = uint32(uint64(u) * uint64(n) >> 32)
+		harness.Sink.Uint32 = uint32(uint64(u) * uint64(n) >> 32)
 		u++
 	}
 }
@@ -43,7 +45,7 @@ func BenchmarkReduceAlternativeNonPowerOfTwo(b *testing.B) {
 		n uint32 = 257
 	)
 	for i := 0; i < b.N; i++ {
-		rGlobal = uint32(uint64(u) * uint64(n) >> 32)
+		harness.Sink.Uint32 = uint32(uint64(u) * uint64(n) >> 32)
 		u++
 	}
 }
@@ -0,0 +1,19 @@
+package compiler
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// reader is a package level variable so the compiler can't see the dynamic
+// type of the interface and resolve the type assertion at compile time.
+var reader io.Reader = new(bytes.Buffer)
+
+func BenchmarkTypeAssertion(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, ok := reader.(*bytes.Buffer); !ok {
+			b.Fatal()
+		}
+	}
+}
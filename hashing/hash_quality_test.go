@@ -0,0 +1,63 @@
+package hashing
+
+import (
+	"flag"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+	"github.com/jeromefroe/golang_benchmarks/internal/hashquality"
+)
+
+var quality = flag.Bool("quality", false, "run the hash quality suite in TestHashQuality")
+
+// qualitySpeedSizes are the input sizes whose throughput is shown next to
+// the quality of each hash.
+var qualitySpeedSizes = []int{16, 4 << 10}
+
+// TestHashQuality runs the SMHasher style tests of the hashquality package
+// against every hash in the registry and prints a matrix of the results
+// next to the throughput of the hash as measured by BenchmarkHashSize. It
+// takes a few seconds per hash, so it only runs with -quality:
+//
+//	go test -v -run TestHashQuality ./hashing -args -quality
+//
+// Weak hashes such as Adler-32 are expected to fail some of the tests, so
+// the verdicts are reported rather than failing the test.
+func TestHashQuality(t *testing.T) {
+	if !*quality {
+		t.Skip("the hash quality suite only runs with -quality")
+	}
+	speeds := make([]string, len(qualitySpeedSizes))
+	for i, size := range qualitySpeedSizes {
+		speeds[i] = "GB/s@" + bench.FormatSize(size)
+	}
+
+	var reports []*hashquality.Report
+	for _, h := range hashes {
+		r := &hashquality.Report{Name: h.Name, Bits: h.Bits, Results: hashquality.Run(h.Quality(), hashquality.DefaultConfig)}
+		for _, size := range qualitySpeedSizes {
+			r.GBPerSec = append(r.GBPerSec, hashThroughput(h, size))
+		}
+		reports = append(reports, r)
+	}
+	if err := hashquality.WriteMatrix(os.Stdout, speeds, reports); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// hashThroughput measures the throughput of h in GB/s on inputs of size
+// bytes, the same way as BenchmarkHashSize.
+func hashThroughput(h Hash, size int) float64 {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(data)
+	sum := h.OneShot()
+	res := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			harness.Sink.Uint128[0], harness.Sink.Uint128[1] = sum(data)
+		}
+	})
+	return float64(size) / (float64(res.T.Nanoseconds()) / float64(res.N))
+}
@@ -0,0 +1,64 @@
+//go:build go1.24
+
+package hashing
+
+import (
+	"hash/maphash"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+)
+
+// comparableKey wraps an input in a struct so that maphash.Comparable hashes
+// it field by field with the hash functions the runtime uses for the keys
+// of a map.
+type comparableKey struct {
+	data string
+	n    int
+}
+
+func init() {
+	hashes = append(hashes, Hash{
+		Name: "maphash_comparable", Bits: 64, Keyed: true,
+		Sum: func(data []byte) (uint64, uint64) {
+			return maphash.Comparable(hashSeed, comparableKey{bytesToString(data), len(data)}), 0
+		},
+	})
+}
+
+// endpoint is a typical composite map key.
+type endpoint struct {
+	host  string
+	port  uint16
+	proto uint8
+	id    uint64
+}
+
+// BenchmarkMaphashStruct hashes a struct with maphash.Comparable, which
+// uses the hash function the runtime generates for the struct as a map key,
+// and by writing each field to a maphash.Hash by hand.
+func BenchmarkMaphashStruct(b *testing.B) {
+	key := endpoint{host: "backend-17.example.com", port: 8443, proto: 6, id: 1 << 40}
+
+	b.Run("comparable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			harness.Sink.Uint64 = maphash.Comparable(hashSeed, key)
+		}
+	})
+
+	b.Run("fields", func(b *testing.B) {
+		var h maphash.Hash
+		h.SetSeed(hashSeed)
+		var buf [11]byte
+		for i := 0; i < b.N; i++ {
+			h.Reset()
+			h.WriteString(key.host)
+			buf[0], buf[1], buf[2] = byte(key.port), byte(key.port>>8), key.proto
+			for j := 0; j < 8; j++ {
+				buf[3+j] = byte(key.id >> (8 * uint(j)))
+			}
+			h.Write(buf[:])
+			harness.Sink.Uint64 = h.Sum64()
+		}
+	})
+}
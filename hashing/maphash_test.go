@@ -0,0 +1,40 @@
+//go:build go1.20
+
+package hashing
+
+import (
+	"hash"
+	"hash/maphash"
+	"unsafe"
+)
+
+// hashSeed seeds the maphash hashes for the run.
+var hashSeed = maphash.MakeSeed()
+
+func init() {
+	hashes = append(hashes,
+		Hash{
+			Name: "maphash", Bits: 64, Keyed: true,
+			Sum: func(data []byte) (uint64, uint64) { return maphash.Bytes(hashSeed, data), 0 },
+			New: func() hash.Hash {
+				h := new(maphash.Hash)
+				h.SetSeed(hashSeed)
+				return h
+			},
+		},
+		Hash{
+			Name: "maphash_string", Bits: 64, Keyed: true,
+			Sum: func(data []byte) (uint64, uint64) { return maphash.String(hashSeed, bytesToString(data)), 0 },
+		},
+	)
+}
+
+// bytesToString returns data as a string without copying it, so that
+// hashing a string isn't charged for the conversion. data must not be
+// modified while the string is in use.
+func bytesToString(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	return unsafe.String(&data[0], len(data))
+}
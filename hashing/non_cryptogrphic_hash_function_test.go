@@ -0,0 +1,122 @@
+package hashing
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+)
+
+// testString is the key hashed by BenchmarkHashOneShot and
+// BenchmarkHashReset.
+const testString = "kahfkgjfq2348r742gydi71382rvkjyaci71138yakdkchvk73773"
+
+var testBytes = []byte(testString)
+
+// hashSizes are the input lengths BenchmarkHashSize hashes, from short map
+// keys to large blobs.
+var hashSizes = []int{4, 8, 16, 32, 64, 256, 1 << 10, 4 << 10, 64 << 10, 1 << 20}
+
+// BenchmarkHashSize hashes random inputs of each of hashSizes with every
+// hash in the registry, reporting throughput with b.SetBytes. The
+// sub-benchmarks are named BenchmarkHashSize/<hash>/size=<bytes>;
+// cmd/crossover turns them into a table of the fastest hash at each size.
+func BenchmarkHashSize(b *testing.B) {
+	axes := []harness.Axis{{Name: "size", Values: hashSizes}}
+	for _, h := range hashes {
+		h := h
+		b.Run(h.Name, func(b *testing.B) {
+			harness.Sweep(b, axes, func(p harness.Params) func(b *testing.B) {
+				data := make([]byte, p.Int("size"))
+				rand.New(rand.NewSource(int64(len(data)))).Read(data)
+				sum := h.OneShot()
+				return func(b *testing.B) {
+					b.SetBytes(int64(len(data)))
+					for i := 0; i < b.N; i++ {
+						harness.Sink.Uint128[0], harness.Sink.Uint128[1] = sum(data)
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkHashOneShot hashes testString with the one-shot function of every
+// hash in the registry which has one.
+func BenchmarkHashOneShot(b *testing.B) {
+	for _, h := range hashes {
+		if h.Sum == nil {
+			continue
+		}
+		sum := h.Sum
+		b.Run(h.Name, func(b *testing.B) {
+			b.SetBytes(int64(len(testBytes)))
+			for i := 0; i < b.N; i++ {
+				harness.Sink.Uint128[0], harness.Sink.Uint128[1] = sum(testBytes)
+			}
+		})
+	}
+}
+
+// BenchmarkHashReset hashes testString with a single streaming hash of every
+// hash in the registry which has one, resetting it before each key. The
+// difference from BenchmarkHashOneShot is the cost of the streaming API.
+func BenchmarkHashReset(b *testing.B) {
+	for _, h := range hashes {
+		if h.New == nil {
+			continue
+		}
+		h := h
+		b.Run(h.Name, func(b *testing.B) {
+			s := h.New()
+			var buf []byte
+			b.SetBytes(int64(len(testBytes)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.Reset()
+				s.Write(testBytes)
+				harness.Sink.Uint128[0], harness.Sink.Uint128[1], buf = h.streamSum(s, buf)
+			}
+		})
+	}
+}
+
+// streamInputSize is the length of the input BenchmarkHashStream hashes.
+const streamInputSize = 1 << 20
+
+// BenchmarkHashStream hashes a 1M input incrementally, writing it to every
+// streaming hash in the registry in chunks of each size, as when hashing a
+// file or a network stream. The sub-benchmarks are named
+// BenchmarkHashStream/<hash>/chunk=<bytes>.
+func BenchmarkHashStream(b *testing.B) {
+	axes := []harness.Axis{{Name: "chunk", Values: []int{16, 64, 256, 1 << 10, 4 << 10, 64 << 10}}}
+	data := make([]byte, streamInputSize)
+	rand.New(rand.NewSource(streamInputSize)).Read(data)
+	for _, h := range hashes {
+		if h.New == nil {
+			continue
+		}
+		h := h
+		b.Run(h.Name, func(b *testing.B) {
+			harness.Sweep(b, axes, func(p harness.Params) func(b *testing.B) {
+				chunk := p.Int("chunk")
+				s := h.New()
+				var buf []byte
+				return func(b *testing.B) {
+					b.SetBytes(streamInputSize)
+					for i := 0; i < b.N; i++ {
+						s.Reset()
+						for off := 0; off < len(data); off += chunk {
+							end := off + chunk
+							if end > len(data) {
+								end = len(data)
+							}
+							s.Write(data[off:end])
+						}
+						harness.Sink.Uint128[0], harness.Sink.Uint128[1], buf = h.streamSum(s, buf)
+					}
+				}
+			})
+		})
+	}
+}
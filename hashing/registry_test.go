@@ -0,0 +1,205 @@
+package hashing
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"hash"
+	"hash/adler32"
+	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/OneOfOne/xxhash"
+	"github.com/dchest/siphash"
+	"github.com/dgryski/go-farm"
+	"github.com/dgryski/go-highway"
+	metro "github.com/dgryski/go-metro"
+	"github.com/dgryski/go-spooky"
+	"github.com/jeromefroe/golang_benchmarks/internal/hashquality"
+	"github.com/spaolacci/murmur3"
+	"github.com/zhenjl/cityhash"
+)
+
+// Hash describes a hash function so that the speed and quality tests can
+// treat every hash the same way, whatever its calling convention.
+type Hash struct {
+	// Name names the sub-benchmarks and rows of the hash, e.g. farm64.
+	Name string
+	// Bits is the width of the output: 32, 64 or 128.
+	Bits int
+	// Keyed is set for hashes which take a key or a seed. The registry
+	// fixes a random one for the run.
+	Keyed bool
+	// Sum hashes a whole input at once, returning outputs of up to 64 bits
+	// in lo. It is nil for hashes which only have a streaming API.
+	Sum func(data []byte) (lo, hi uint64)
+	// New returns a streaming hash, or is nil if the implementation has no
+	// streaming API.
+	New func() hash.Hash
+}
+
+// OneShot returns a function which hashes a whole input: Sum if the hash
+// has one, and otherwise a single streaming hash which is reset before each
+// input. The function is not safe for concurrent use.
+func (h Hash) OneShot() func(data []byte) (lo, hi uint64) {
+	if h.Sum != nil {
+		return h.Sum
+	}
+	s := h.New()
+	var buf []byte
+	return func(data []byte) (uint64, uint64) {
+		s.Reset()
+		s.Write(data)
+		var lo, hi uint64
+		lo, hi, buf = h.streamSum(s, buf)
+		return lo, hi
+	}
+}
+
+// Quality returns h as tested by the hashquality package.
+func (h Hash) Quality() hashquality.Hash {
+	return hashquality.Hash{Name: h.Name, Bits: h.Bits, Sum: h.OneShot()}
+}
+
+// hash128 is implemented by streaming hashes with a 128-bit output, such as
+// murmur3.Hash128.
+type hash128 interface {
+	hash.Hash
+	Sum128() (uint64, uint64)
+}
+
+// streamSum returns the current output of s, a streaming hash of h, without
+// allocating where the hash has a method returning an output of its width,
+// and otherwise decoding s.Sum into buf. The method is chosen by h.Bits
+// rather than by the interfaces s implements, since a 128-bit digest may
+// embed a 64-bit one and inherit its Sum64.
+func (h Hash) streamSum(s hash.Hash, buf []byte) (lo, hi uint64, _ []byte) {
+	switch h.Bits {
+	case 32:
+		if s, ok := s.(hash.Hash32); ok {
+			return uint64(s.Sum32()), 0, buf
+		}
+	case 64:
+		if s, ok := s.(hash.Hash64); ok {
+			return s.Sum64(), 0, buf
+		}
+	case 128:
+		if s, ok := s.(hash128); ok {
+			lo, hi = s.Sum128()
+			return lo, hi, buf
+		}
+	}
+	buf = s.Sum(buf[:0])
+	switch {
+	case len(buf) >= 16:
+		return binary.LittleEndian.Uint64(buf), binary.LittleEndian.Uint64(buf[8:]), buf
+	case len(buf) >= 8:
+		return binary.LittleEndian.Uint64(buf), 0, buf
+	}
+	return uint64(binary.LittleEndian.Uint32(buf)), 0, buf
+}
+
+// The key and seeds of the keyed hashes.
+var hashKey0, hashKey1 = rand.Uint64(), rand.Uint64()
+
+var crc64Table = crc64.MakeTable(crc64.ISO)
+
+// sipKey returns hashKey0 and hashKey1 as the 16 byte key of siphash.New.
+func sipKey() []byte {
+	key := make([]byte, 16)
+	binary.LittleEndian.PutUint64(key, hashKey0)
+	binary.LittleEndian.PutUint64(key[8:], hashKey1)
+	return key
+}
+
+// hashes is the registry of every hash under test.
+var hashes = []Hash{
+	{Name: "fnv32", Bits: 32, New: func() hash.Hash { return fnv.New32() }},
+	{Name: "fnv32a", Bits: 32, New: func() hash.Hash { return fnv.New32a() }},
+	{Name: "fnv64", Bits: 64, New: func() hash.Hash { return fnv.New64() }},
+	{Name: "fnv64a", Bits: 64, New: func() hash.Hash { return fnv.New64a() }},
+	{
+		Name: "crc32", Bits: 32,
+		Sum: func(data []byte) (uint64, uint64) { return uint64(crc32.ChecksumIEEE(data)), 0 },
+		New: func() hash.Hash { return crc32.NewIEEE() },
+	},
+	{
+		Name: "crc64", Bits: 64,
+		Sum: func(data []byte) (uint64, uint64) { return crc64.Checksum(data, crc64Table), 0 },
+		New: func() hash.Hash { return crc64.New(crc64Table) },
+	},
+	{
+		Name: "adler32", Bits: 32,
+		Sum: func(data []byte) (uint64, uint64) { return uint64(adler32.Checksum(data)), 0 },
+		New: func() hash.Hash { return adler32.New() },
+	},
+	{
+		Name: "xxhash32", Bits: 32,
+		Sum: func(data []byte) (uint64, uint64) { return uint64(xxhash.Checksum32(data)), 0 },
+		New: func() hash.Hash { return xxhash.New32() },
+	},
+	{
+		Name: "xxhash64", Bits: 64,
+		Sum: func(data []byte) (uint64, uint64) { return xxhash.Checksum64(data), 0 },
+		New: func() hash.Hash { return xxhash.New64() },
+	},
+	{
+		Name: "murmur3_32", Bits: 32,
+		Sum: func(data []byte) (uint64, uint64) { return uint64(murmur3.Sum32(data)), 0 },
+		New: func() hash.Hash { return murmur3.New32() },
+	},
+	{
+		Name: "murmur3_128", Bits: 128,
+		Sum: murmur3.Sum128,
+		New: func() hash.Hash { return murmur3.New128() },
+	},
+	{Name: "city64", Bits: 64, Sum: func(data []byte) (uint64, uint64) {
+		return cityhash.CityHash64(data, uint32(len(data))), 0
+	}},
+	{Name: "city128", Bits: 128, Sum: func(data []byte) (uint64, uint64) {
+		h := cityhash.CityHash128(data, uint32(len(data)))
+		return h[0], h[1]
+	}},
+	{Name: "farm32", Bits: 32, Sum: func(data []byte) (uint64, uint64) { return uint64(farm.Hash32(data)), 0 }},
+	{Name: "farm64", Bits: 64, Sum: func(data []byte) (uint64, uint64) { return farm.Hash64(data), 0 }},
+	{Name: "farm128", Bits: 128, Sum: farm.Hash128},
+	{
+		Name: "siphash64", Bits: 64, Keyed: true,
+		Sum: func(data []byte) (uint64, uint64) { return siphash.Hash(hashKey0, hashKey1, data), 0 },
+		New: func() hash.Hash { return siphash.New(sipKey()) },
+	},
+	{
+		Name: "siphash128", Bits: 128, Keyed: true,
+		Sum: func(data []byte) (uint64, uint64) { return siphash.Hash128(hashKey0, hashKey1, data) },
+		New: func() hash.Hash { return siphash.New128(sipKey()) },
+	},
+	{Name: "highway64", Bits: 64, Keyed: true, Sum: func(data []byte) (uint64, uint64) {
+		return highway.Hash(highway.Lanes{hashKey0, hashKey1, hashKey0, hashKey1}, data), 0
+	}},
+	{Name: "spooky32", Bits: 32, Sum: func(data []byte) (uint64, uint64) { return uint64(spooky.Hash32(data)), 0 }},
+	{Name: "spooky64", Bits: 64, Sum: func(data []byte) (uint64, uint64) { return spooky.Hash64(data), 0 }},
+	{
+		Name: "spooky128", Bits: 128, Keyed: true,
+		Sum: func(data []byte) (uint64, uint64) {
+			h0, h1 := hashKey0, hashKey1
+			spooky.Hash128(data, &h0, &h1)
+			return h0, h1
+		},
+		New: func() hash.Hash { return spooky.New(hashKey0, hashKey1) },
+	},
+	{
+		Name: "md5", Bits: 128,
+		Sum: func(data []byte) (uint64, uint64) {
+			sum := md5.Sum(data)
+			return binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:])
+		},
+		New: md5.New,
+	},
+	{Name: "metro64", Bits: 64, Keyed: true, Sum: func(data []byte) (uint64, uint64) {
+		return metro.Hash64(data, hashKey0), 0
+	}},
+	{Name: "metro128", Bits: 128, Keyed: true, Sum: func(data []byte) (uint64, uint64) {
+		return metro.Hash128(data, hashKey0)
+	}},
+}
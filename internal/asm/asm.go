@@ -0,0 +1,201 @@
+// Package asm extracts the compiled benchmark loop of each benchmark from
+// the disassembly of the test binary and renders pairs of them side by side.
+package asm
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/dce"
+)
+
+// Listing is the compiled benchmark loop of a benchmark, without the
+// instructions which run the loop itself, i.e. the loop counter, b.N and
+// pb.Next, and without padding.
+type Listing struct {
+	Benchmark string
+	File      string
+	Line      int
+	Insts     []dce.Inst
+}
+
+// Extract returns the listing of every benchmark with a benchmark loop in
+// loops which is found in funcs, by benchmark name. If a benchmark has
+// several benchmark loops, e.g. one per sub-benchmark, the first is used.
+func Extract(loops []dce.Loop, funcs []dce.Func) map[string]*Listing {
+	hot := make(map[string]dce.Loop)
+	for _, l := range loops {
+		if _, ok := hot[l.Benchmark]; !ok && l.Hot {
+			hot[l.Benchmark] = l
+		}
+	}
+
+	listings := make(map[string]*Listing)
+	for _, f := range funcs {
+		name := dce.Benchmark(f.Name)
+		l, ok := hot[name]
+		if !ok || listings[name] != nil {
+			continue
+		}
+		insts := loopInsts(f, l)
+		if insts == nil {
+			continue
+		}
+		listings[name] = &Listing{Benchmark: name, File: l.File, Line: l.Line, Insts: insts}
+	}
+	return listings
+}
+
+// loopInsts returns the instructions of l in f, or nil if l isn't in f. The
+// loop spans from the first to the last instruction attributed to its lines,
+// which includes any code inlined into it.
+func loopInsts(f dce.Func, l dce.Loop) []dce.Inst {
+	first, last := -1, -1
+	for i, inst := range f.Insts {
+		if inst.File == l.File && inst.Line >= l.Line && inst.Line <= l.Last {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	insts := []dce.Inst{}
+	for _, inst := range f.Insts[first : last+1] {
+		if isNoise(inst, l) {
+			continue
+		}
+		insts = append(insts, inst)
+	}
+	return insts
+}
+
+// benchmarkFile is the file of package testing which b.N and pb.Next are
+// declared in.
+const benchmarkFile = "benchmark.go"
+
+// isNoise reports whether inst runs the benchmark loop rather than doing
+// the work being measured, or is padding.
+func isNoise(inst dce.Inst, l dce.Loop) bool {
+	switch {
+	case inst.File == benchmarkFile:
+		return true
+	case strings.HasPrefix(inst.Asm, "NOP"), inst.Asm == "INT $0x3":
+		return true
+	case inst.File == l.File && inst.Line == l.Line:
+		return !dce.IsWork(inst)
+	}
+	return false
+}
+
+// Len returns the number of instructions in the listing.
+func (l *Listing) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.Insts)
+}
+
+// importPathRE matches the import path of a symbol up to its package name,
+// e.g. github.com/jeromefroe/golang_benchmarks/internal/ in
+// github.com/jeromefroe/golang_benchmarks/internal/harness.Sink.
+var importPathRE = regexp.MustCompile(`(?:[\w.-]+/)+([\w-]+\.)`)
+
+// Lines returns the listing as text, one instruction per line prefixed with
+// its source position. The file is omitted for lines of the benchmark's own
+// file and symbols are shortened to their package name.
+func (l *Listing) Lines() []string {
+	if l == nil {
+		return []string{"(not found)"}
+	}
+	lines := make([]string, len(l.Insts))
+	for i, inst := range l.Insts {
+		pos := strconv.Itoa(inst.Line)
+		if inst.File != l.File {
+			pos = inst.File + ":" + pos
+		}
+		lines[i] = fmt.Sprintf("%-6s %s", pos, importPathRE.ReplaceAllString(inst.Asm, "$1"))
+	}
+	return lines
+}
+
+// Pair is the listings of a declared pair of variants.
+type Pair struct {
+	A, B         string
+	ListA, ListB *Listing
+}
+
+// WriteText writes the listings of each pair side by side, each column
+// truncated to width characters.
+func WriteText(w io.Writer, pairs []Pair, width int) error {
+	for _, p := range pairs {
+		a, b := p.ListA.Lines(), p.ListB.Lines()
+		row := func(left, right string) {
+			fmt.Fprintf(w, "%-*s | %s\n", width, truncate(left, width), truncate(right, width))
+		}
+		row(fmt.Sprintf("%s (%d instructions)", p.A, p.ListA.Len()), fmt.Sprintf("%s (%d instructions)", p.B, p.ListB.Len()))
+		row(strings.Repeat("-", width), strings.Repeat("-", width))
+		for i := 0; i < len(a) || i < len(b); i++ {
+			var left, right string
+			if i < len(a) {
+				left = a[i]
+			}
+			if i < len(b) {
+				right = b[i]
+			}
+			row(left, right)
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func truncate(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return s[:width-1] + "…"
+}
+
+// WriteHTML writes the listings of each pair side by side as a standalone
+// HTML page.
+func WriteHTML(w io.Writer, pairs []Pair) error {
+	return pageTemplate.Execute(w, pairs)
+}
+
+var pageTemplate = template.Must(template.New("asm").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Benchmark Loops</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th { text-align: left; padding: 0.3em 1em; border-bottom: 1px solid #ddd; }
+td { vertical-align: top; padding: 0 1em; }
+pre { margin: 0.5em 0; font-size: 0.85em; }
+.count { color: #666; font-weight: normal; }
+</style>
+</head>
+<body>
+<h1>Benchmark Loops</h1>
+{{range .}}
+<table>
+<tr><th>{{.A}} <span class="count">{{.ListA.Len}} instructions</span></th><th>{{.B}} <span class="count">{{.ListB.Len}} instructions</span></th></tr>
+<tr><td><pre>{{range .ListA.Lines}}{{.}}
+{{end}}</pre></td><td><pre>{{range .ListB.Lines}}{{.}}
+{{end}}</pre></td></tr>
+</table>
+{{end}}
+</body>
+</html>
+`))
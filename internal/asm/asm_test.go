@@ -0,0 +1,71 @@
+package asm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/dce"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleObjdump = `TEXT example.com/pkg.BenchmarkAnd(SB) /src/pkg/x_test.go
+  x_test.go:5		0x1000			31c9			XORL CX, CX
+  x_test.go:6		0x1002			eb0a			JMP 0x100e
+  x_test.go:7		0x1004			4889ca			MOVQ CX, DX
+  x_test.go:7		0x1007			81e200010000		ANDL $0x100, DX
+  x_test.go:7		0x100d			90			NOPL
+  x_test.go:7		0x100e			488915000000		MOVQ DX, example.com/pkg.global(SB)
+  x_test.go:6		0x1015			48ffc1			INCQ CX
+  x_test.go:6		0x1018			483908			CMPQ CX, 0x1b0(AX)
+  x_test.go:6		0x101f			7fe3			JG 0x1004
+  x_test.go:9		0x1021			c3			RET
+TEXT example.com/pkg.BenchmarkNext.func1(SB) /src/pkg/x_test.go
+  x_test.go:13		0x2000			eb05			JMP 0x2007
+  benchmark.go:933	0x2002			48ff4810		DECQ 0x10(AX)
+  x_test.go:14		0x2006			e800000000		CALL example.com/pkg.work(SB)
+  benchmark.go:923	0x200b			75f5			JNE 0x2002
+`
+
+func TestExtract(t *testing.T) {
+	funcs, err := dce.ParseObjdump(strings.NewReader(sampleObjdump))
+	assert.NoError(t, err)
+	loops := []dce.Loop{
+		{File: "x_test.go", Benchmark: "BenchmarkAnd", Line: 6, First: 7, Last: 7, Hot: true},
+		{File: "x_test.go", Benchmark: "BenchmarkNext", Line: 13, First: 14, Last: 14, Hot: true},
+		{File: "x_test.go", Benchmark: "BenchmarkMissing", Line: 20, First: 21, Last: 21, Hot: true},
+	}
+	listings := Extract(loops, funcs)
+	assert.Len(t, listings, 2)
+
+	and := listings["BenchmarkAnd"]
+	assert.Equal(t, 3, and.Len())
+	assert.Equal(t, []string{
+		"7      MOVQ CX, DX",
+		"7      ANDL $0x100, DX",
+		"7      MOVQ DX, pkg.global(SB)",
+	}, and.Lines())
+
+	next := listings["BenchmarkNext"]
+	assert.Equal(t, 1, next.Len())
+	assert.Equal(t, "CALL example.com/pkg.work(SB)", next.Insts[0].Asm)
+
+	var missing *Listing
+	assert.Equal(t, 0, missing.Len())
+}
+
+func TestWrite(t *testing.T) {
+	funcs, err := dce.ParseObjdump(strings.NewReader(sampleObjdump))
+	assert.NoError(t, err)
+	listings := Extract([]dce.Loop{{File: "x_test.go", Benchmark: "BenchmarkAnd", Line: 6, First: 7, Last: 7, Hot: true}}, funcs)
+	pairs := []Pair{{A: "BenchmarkAnd", B: "BenchmarkMissing", ListA: listings["BenchmarkAnd"]}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, pairs, 30))
+	assert.Contains(t, buf.String(), "BenchmarkAnd (3 instructions)")
+	assert.Contains(t, buf.String(), "(not found)")
+
+	buf.Reset()
+	assert.NoError(t, WriteHTML(&buf, pairs))
+	assert.Contains(t, buf.String(), "ANDL $0x100, DX")
+}
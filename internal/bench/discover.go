@@ -0,0 +1,195 @@
+package bench
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// File is a benchmark source file and the benchmarks it declares, in
+// source order.
+type File struct {
+	// Name is the slash separated path of the file relative to the
+	// directory passed to Discover, e.g. sync/mutex_test.go.
+	Name       string
+	Benchmarks []string
+	// Parallel lists the benchmarks which call b.RunParallel.
+	Parallel []string
+}
+
+// Package returns the directory of the package declaring f, relative to the
+// directory passed to Discover.
+func (f File) Package() string {
+	return path.Dir(f.Name)
+}
+
+// Packages returns the slash separated paths, relative to root, of the
+// directories under root which contain _test.go files, sorted. The cmd,
+// internal, testdata and vendor directories and hidden directories are
+// skipped, so from the root of the repository these are the topic packages.
+func Packages(root string) ([]string, error) {
+	var pkgs []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." {
+			switch name := info.Name(); {
+			case name == "cmd", name == "internal", name == "testdata", name == "vendor",
+				strings.HasPrefix(name, "."), strings.HasPrefix(name, "_"):
+				return filepath.SkipDir
+			}
+		}
+		tests, err := filepath.Glob(filepath.Join(p, "*_test.go"))
+		if err != nil {
+			return err
+		}
+		if len(tests) > 0 {
+			pkgs = append(pkgs, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(pkgs)
+	return pkgs, err
+}
+
+// Discover returns every _test.go file in the packages under root which
+// declares at least one benchmark, sorted by path.
+func Discover(root string) ([]File, error) {
+	pkgs, err := Packages(root)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, pkg := range pkgs {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pkg), "*_test.go"))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var files []File
+	fset := token.NewFileSet()
+	for _, p := range paths {
+		f, err := parser.ParseFile(fset, p, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil, err
+		}
+		file := File{Name: filepath.ToSlash(rel)}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !IsBenchmark(fn) {
+				continue
+			}
+			file.Benchmarks = append(file.Benchmarks, fn.Name.Name)
+			if callsRunParallel(fn) {
+				file.Parallel = append(file.Parallel, fn.Name.Name)
+			}
+		}
+		if len(file.Benchmarks) > 0 {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// Benchmark is a top-level benchmark and the package declaring it, relative
+// to the directory passed to Select.
+type Benchmark struct {
+	Package, Name string
+}
+
+// Select returns the benchmarks in the packages under root whose names match
+// re, in the order of Discover, or an error if none match.
+func Select(root string, re *regexp.Regexp) ([]Benchmark, error) {
+	files, err := Discover(root)
+	if err != nil {
+		return nil, err
+	}
+	var selected []Benchmark
+	for _, f := range files {
+		for _, name := range f.Benchmarks {
+			if re.MatchString(name) {
+				selected = append(selected, Benchmark{Package: f.Package(), Name: name})
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no benchmarks match %s", re)
+	}
+	return selected, nil
+}
+
+// IsBenchmark reports whether fn looks like func BenchmarkXxx(b *testing.B).
+func IsBenchmark(fn *ast.FuncDecl) bool {
+	if fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Benchmark") {
+		return false
+	}
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "B"
+}
+
+// callsRunParallel reports whether the body of fn calls RunParallel on its
+// *testing.B parameter.
+func callsRunParallel(fn *ast.FuncDecl) bool {
+	names := fn.Type.Params.List[0].Names
+	if len(names) == 0 || fn.Body == nil {
+		return false
+	}
+	param := names[0].Name
+
+	found := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "RunParallel" {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok && id.Name == param {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// Regexp returns a -bench pattern which matches exactly the given top-level
+// benchmarks and all of their sub-benchmarks.
+func Regexp(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
@@ -0,0 +1,63 @@
+package bench
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscover(t *testing.T) {
+	files, err := Discover("../..")
+	assert.NoError(t, err)
+
+	byName := make(map[string]File)
+	for _, f := range files {
+		byName[f.Name] = f
+	}
+
+	mutex := byName["sync/mutex_test.go"]
+	assert.Equal(t, []string{
+		"BenchmarkNoMutexLock",
+		"BenchmarkRWMutexReadLock",
+		"BenchmarkRWMutexLock",
+		"BenchmarkMutexLock",
+	}, mutex.Benchmarks)
+	assert.Equal(t, mutex.Benchmarks, mutex.Parallel)
+
+	assert.Equal(t, "sync", mutex.Package())
+
+	deferFile := byName["sync/defer_test.go"]
+	assert.Len(t, deferFile.Benchmarks, 2)
+	assert.Empty(t, deferFile.Parallel)
+}
+
+func TestSelect(t *testing.T) {
+	selected, err := Select("../..", regexp.MustCompile(`^Benchmark(No|RW)?Mutex(Read)?Lock$`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Benchmark{
+		{Package: "sync", Name: "BenchmarkNoMutexLock"},
+		{Package: "sync", Name: "BenchmarkRWMutexReadLock"},
+		{Package: "sync", Name: "BenchmarkRWMutexLock"},
+		{Package: "sync", Name: "BenchmarkMutexLock"},
+	}, selected)
+
+	_, err = Select("../..", regexp.MustCompile("^BenchmarkMissing$"))
+	assert.Error(t, err)
+}
+
+func TestPackages(t *testing.T) {
+	pkgs, err := Packages("../..")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alloc", "bitsets", "compiler", "hashing", "maps", "pools", "queues", "strings", "sync"}, pkgs)
+
+	pkgs, err = Packages("../../sync")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"."}, pkgs)
+	assert.Equal(t, []string{"."}, PackageArgs(pkgs))
+	assert.Equal(t, []string{"./alloc", "./sync"}, PackageArgs([]string{"alloc", "sync"}))
+}
+
+func TestRegexp(t *testing.T) {
+	assert.Equal(t, `^(BenchmarkA|BenchmarkB)$`, Regexp([]string{"BenchmarkA", "BenchmarkB"}))
+}
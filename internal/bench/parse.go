@@ -0,0 +1,154 @@
+// Package bench contains helpers for discovering, running and parsing the
+// benchmarks in this repository.
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Result is a single benchmark result line as printed by go test.
+type Result struct {
+	// Name is the full benchmark name without the GOMAXPROCS suffix,
+	// e.g. BenchmarkSliceClearZero/len=1K.
+	Name        string  `json:"name"`
+	Procs       int     `json:"procs"`
+	Iterations  int     `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+	// Metrics holds any other reported units, such as MB/s or the custom
+	// metrics reported with b.ReportMetric, keyed by unit.
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// Value returns the value r reported for unit and whether it was reported.
+func (r *Result) Value(unit string) (float64, bool) {
+	switch unit {
+	case "ns/op":
+		return r.NsPerOp, true
+	case "B/op":
+		return float64(r.BytesPerOp), true
+	case "allocs/op":
+		return float64(r.AllocsPerOp), true
+	}
+	v, ok := r.Metrics[unit]
+	return v, ok
+}
+
+// Set is the parsed output of a go test -bench invocation.
+type Set struct {
+	// Config holds the key-value header lines printed by go test, such as
+	// goos, goarch, pkg and cpu.
+	Config  map[string]string
+	Results []*Result
+}
+
+// Lookup returns the results with the given name, in the order they were
+// reported.
+func (s *Set) Lookup(name string) []*Result {
+	var rs []*Result
+	for _, r := range s.Results {
+		if r.Name == name {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// Parse reads go test -bench output from r. Lines which are neither
+// configuration nor benchmark results are ignored.
+func Parse(r io.Reader) (*Set, error) {
+	s := &Set{Config: make(map[string]string)}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "Benchmark") {
+			res, err := parseResult(line)
+			if err != nil {
+				return nil, err
+			}
+			if res != nil {
+				s.Results = append(s.Results, res)
+			}
+			continue
+		}
+		if i := strings.Index(line, ": "); i > 0 && !strings.ContainsAny(line[:i], " \t") {
+			s.Config[line[:i]] = strings.TrimSpace(line[i+2:])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// parseResult parses a single result line. It returns nil if the line only
+// names a benchmark, as go test -v does before running it.
+func parseResult(line string) (*Result, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, nil
+	}
+
+	res := &Result{Iterations: n, Procs: 1}
+	res.Name, res.Procs = splitProcs(fields[0])
+	for i := 2; i+1 < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bench: invalid value %q in %q", fields[i], line)
+		}
+		switch unit := fields[i+1]; unit {
+		case "ns/op":
+			res.NsPerOp = v
+		case "B/op":
+			res.BytesPerOp = int64(v)
+		case "allocs/op":
+			res.AllocsPerOp = int64(v)
+		default:
+			if res.Metrics == nil {
+				res.Metrics = make(map[string]float64)
+			}
+			res.Metrics[unit] = v
+		}
+	}
+	return res, nil
+}
+
+// splitProcs splits the -N GOMAXPROCS suffix go test appends to benchmark
+// names.
+func splitProcs(name string) (string, int) {
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return name, 1
+	}
+	procs, err := strconv.Atoi(name[i+1:])
+	if err != nil || procs <= 0 {
+		return name, 1
+	}
+	return name[:i], procs
+}
+
+// FormatValue formats v with the same precision go test uses for ns/op.
+func FormatValue(v float64) string {
+	switch y := v; {
+	case y == 0 || y >= 99.995 || y <= -99.995:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case y >= 9.9995 || y <= -9.9995:
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	case y >= 0.99995 || y <= -0.99995:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case y >= 0.099995 || y <= -0.099995:
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	default:
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+}
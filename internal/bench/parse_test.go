@@ -0,0 +1,46 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleOutput = `goos: linux
+goarch: amd64
+pkg: github.com/jeromefroe/golang_benchmarks
+cpu: Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz
+BenchmarkAllocateFooHeap-8         	50000000	        29.0 ns/op	      32 B/op	       1 allocs/op
+BenchmarkSliceClearZero/16K-8      	10000000	         167 ns/op
+BenchmarkHash64Xxhash              	30000000	        47.4 ns/op	1118.10 MB/s
+PASS
+ok  	github.com/jeromefroe/golang_benchmarks	12.345s
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse(strings.NewReader(sampleOutput))
+	assert.NoError(t, err)
+	assert.Equal(t, "linux", s.Config["goos"])
+	assert.Equal(t, "Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz", s.Config["cpu"])
+	assert.Len(t, s.Results, 3)
+
+	assert.Equal(t, &Result{
+		Name:        "BenchmarkAllocateFooHeap",
+		Procs:       8,
+		Iterations:  50000000,
+		NsPerOp:     29.0,
+		BytesPerOp:  32,
+		AllocsPerOp: 1,
+	}, s.Results[0])
+	assert.Equal(t, "BenchmarkSliceClearZero/16K", s.Results[1].Name)
+	assert.Equal(t, 1, s.Results[2].Procs)
+	assert.Equal(t, 1118.10, s.Results[2].Metrics["MB/s"])
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "1046144", FormatValue(1046144))
+	assert.Equal(t, "29.0", FormatValue(29))
+	assert.Equal(t, "2.27", FormatValue(2.27))
+	assert.Equal(t, "0.840", FormatValue(0.84))
+}
@@ -0,0 +1,105 @@
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Options configures a go test -bench invocation.
+type Options struct {
+	// Dir is the directory to run go test in, defaults to ".".
+	Dir string
+	// Packages are the packages to benchmark, relative to Dir. They default
+	// to every package under Dir with tests, see Packages.
+	Packages []string
+	// Bench is the -bench regexp, defaults to ".".
+	Bench     string
+	Benchtime string
+	Count     int
+	CPU       string
+	// Args are extra arguments passed to go test before the package.
+	Args []string
+	// Env is appended to the environment of the go command.
+	Env []string
+	// Output, if set, receives a copy of the raw go test output.
+	Output io.Writer
+}
+
+// Run runs the benchmarks selected by opts with -benchmem and parses the
+// results.
+func Run(opts Options) (*Set, error) {
+	out, err := RunRaw(opts)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(out))
+}
+
+// RunRaw is like Run but returns the unparsed go test output.
+func RunRaw(opts Options) ([]byte, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	pattern := opts.Bench
+	if pattern == "" {
+		pattern = "."
+	}
+
+	args := []string{"test", "-run", "^$", "-bench", pattern, "-benchmem"}
+	if opts.Benchtime != "" {
+		args = append(args, "-benchtime", opts.Benchtime)
+	}
+	if opts.Count > 0 {
+		args = append(args, "-count", strconv.Itoa(opts.Count))
+	}
+	if opts.CPU != "" {
+		args = append(args, "-cpu", opts.CPU)
+	}
+	args = append(args, opts.Args...)
+	pkgs := opts.Packages
+	if len(pkgs) == 0 {
+		var err error
+		if pkgs, err = Packages(dir); err != nil {
+			return nil, err
+		}
+	}
+	args = append(args, PackageArgs(pkgs)...)
+
+	var buf bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), opts.Env...)
+	if opts.Output != nil {
+		cmd.Stdout = io.MultiWriter(&buf, opts.Output)
+	} else {
+		cmd.Stdout = &buf
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("bench: go %s: %v\n%s", strings.Join(args, " "), err, buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// PackageArgs returns the go command arguments naming the packages in the
+// slash separated directories pkgs, or "." if there are none.
+func PackageArgs(pkgs []string) []string {
+	if len(pkgs) == 0 {
+		return []string{"."}
+	}
+	args := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		if pkg == "." {
+			args[i] = "."
+		} else {
+			args[i] = "./" + pkg
+		}
+	}
+	return args
+}
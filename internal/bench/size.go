@@ -0,0 +1,35 @@
+package bench
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatSize formats v as used in sub-benchmark names: multiples of 1M and
+// 1K are written with an M or K suffix and other values in decimal.
+func FormatSize(v int) string {
+	switch {
+	case v != 0 && v%(1<<20) == 0:
+		return strconv.Itoa(v>>20) + "M"
+	case v != 0 && v%(1<<10) == 0:
+		return strconv.Itoa(v>>10) + "K"
+	}
+	return strconv.Itoa(v)
+}
+
+// ParseSize parses values such as 1000, 16K or 1M, as written by FormatSize.
+func ParseSize(s string) (int, error) {
+	num, mult := strings.TrimSpace(s), 1
+	switch {
+	case strings.HasSuffix(num, "K"):
+		mult, num = 1<<10, strings.TrimSuffix(num, "K")
+	case strings.HasSuffix(num, "M"):
+		mult, num = 1<<20, strings.TrimSuffix(num, "M")
+	}
+	v, err := strconv.Atoi(num)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return v * mult, nil
+}
@@ -0,0 +1,23 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSize(t *testing.T) {
+	for s, want := range map[string]int{"0": 0, "1000": 1000, "1024": 1024, "16K": 16 << 10, "1M": 1 << 20} {
+		got, err := ParseSize(s)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseSize("K")
+	assert.Error(t, err)
+
+	assert.Equal(t, "0", FormatSize(0))
+	assert.Equal(t, "1000", FormatSize(1000))
+	assert.Equal(t, "1K", FormatSize(1024))
+	assert.Equal(t, "1536", FormatSize(1536))
+	assert.Equal(t, "2M", FormatSize(2<<20))
+}
@@ -0,0 +1,177 @@
+// Package catalog describes every benchmark in the repository and tags it by
+// topic so that related benchmarks can be listed and run together.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+)
+
+// Tags are the tags a benchmark may declare.
+var Tags = []string{
+	"allocation",
+	"arithmetic",
+	"compiler-optimization",
+	"concurrency",
+	"hashing",
+	"memory",
+	"third-party",
+}
+
+// Entry describes a single top-level benchmark.
+type Entry struct {
+	// File is the source file declaring the benchmark, relative to the root
+	// of the repository.
+	File        string   `json:"file"`
+	Benchmark   string   `json:"benchmark"`
+	Tags        []string `json:"tags"`
+	Description string   `json:"description"`
+}
+
+// HasTag reports whether e declares tag.
+func (e Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads the catalog in the JSON file at path and checks that every
+// entry has a description and only known tags.
+func Load(path string) ([]Entry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("catalog: %s: %v", path, err)
+	}
+	known := make(map[string]bool, len(Tags))
+	for _, t := range Tags {
+		known[t] = true
+	}
+	for _, e := range entries {
+		if e.Description == "" {
+			return nil, fmt.Errorf("catalog: %s: %s has no description", path, e.Benchmark)
+		}
+		for _, t := range e.Tags {
+			if !known[t] {
+				return nil, fmt.Errorf("catalog: %s: %s has unknown tag %q", path, e.Benchmark, t)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// Check compares the catalog with the benchmarks declared in files and
+// returns a problem for each benchmark missing from the catalog and each
+// entry which doesn't match a benchmark in its file.
+func Check(entries []Entry, files []bench.File) []string {
+	declared := make(map[string]bool)
+	for _, f := range files {
+		for _, name := range f.Benchmarks {
+			declared[f.Name+" "+name] = true
+		}
+	}
+	listed := make(map[string]bool)
+	var problems []string
+	for _, e := range entries {
+		key := e.File + " " + e.Benchmark
+		switch {
+		case listed[key]:
+			problems = append(problems, fmt.Sprintf("%s: %s is listed more than once", e.File, e.Benchmark))
+		case !declared[key]:
+			problems = append(problems, fmt.Sprintf("%s: %s is not declared in the file", e.File, e.Benchmark))
+		}
+		listed[key] = true
+	}
+	for _, f := range files {
+		for _, name := range f.Benchmarks {
+			if !listed[f.Name+" "+name] {
+				problems = append(problems, fmt.Sprintf("%s: %s is missing from the catalog", f.Name, name))
+			}
+		}
+	}
+	return problems
+}
+
+// Filter returns the entries which declare all of tags, in their original
+// order.
+func Filter(entries []Entry, tags []string) []Entry {
+	var out []Entry
+	for _, e := range entries {
+		ok := true
+		for _, t := range tags {
+			if !e.HasTag(t) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Counts returns the number of entries declaring each tag.
+func Counts(entries []Entry) map[string]int {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		for _, t := range e.Tags {
+			counts[t]++
+		}
+	}
+	return counts
+}
+
+// Names returns the sorted names of the benchmarks of entries.
+func Names(entries []Entry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Benchmark
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Packages returns the sorted directories of the packages declaring entries.
+func Packages(entries []Entry) []string {
+	var pkgs []string
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if pkg := path.Dir(e.File); !seen[pkg] {
+			seen[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// Command returns the go test command which runs exactly the benchmarks of
+// entries, and no tests, from the root of the repository.
+func Command(entries []Entry) string {
+	return fmt.Sprintf("go test -run '^$' -bench '%s' %s",
+		bench.Regexp(Names(entries)), strings.Join(bench.PackageArgs(Packages(entries)), " "))
+}
+
+// ParseTags splits a comma separated list of tags.
+func ParseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,65 @@
+package catalog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/stretchr/testify/assert"
+)
+
+var entries = []Entry{
+	{File: "sync/mutex_test.go", Benchmark: "BenchmarkMutexLock", Tags: []string{"concurrency"}},
+	{File: "pools/pool_test.go", Benchmark: "BenchmarkSyncBufferPool", Tags: []string{"allocation", "concurrency"}},
+	{File: "pools/pool_test.go", Benchmark: "BenchmarkAllocateBufferNoPool", Tags: []string{"allocation"}},
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catalog")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "catalog.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`[{"file": "mutex_test.go", "benchmark": "BenchmarkMutexLock", "tags": ["concurrency"], "description": "lock"}]`), 0644))
+	got, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{File: "mutex_test.go", Benchmark: "BenchmarkMutexLock", Tags: []string{"concurrency"}, Description: "lock"}}, got)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`[{"file": "mutex_test.go", "benchmark": "BenchmarkMutexLock", "tags": ["locks"], "description": "lock"}]`), 0644))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	files := []bench.File{
+		{Name: "sync/mutex_test.go", Benchmarks: []string{"BenchmarkMutexLock", "BenchmarkRWMutexLock"}},
+		{Name: "pools/pool_test.go", Benchmarks: []string{"BenchmarkSyncBufferPool"}},
+	}
+	assert.Equal(t, []string{
+		"pools/pool_test.go: BenchmarkAllocateBufferNoPool is not declared in the file",
+		"sync/mutex_test.go: BenchmarkRWMutexLock is missing from the catalog",
+	}, Check(entries, files))
+}
+
+func TestFilter(t *testing.T) {
+	assert.Len(t, Filter(entries, nil), 3)
+	assert.Equal(t, entries[1:], Filter(entries, []string{"allocation"}))
+	assert.Equal(t, entries[1:2], Filter(entries, []string{"allocation", "concurrency"}))
+	assert.Equal(t, map[string]int{"allocation": 2, "concurrency": 2}, Counts(entries))
+}
+
+func TestCommand(t *testing.T) {
+	assert.Equal(t, `go test -run '^$' -bench '^(BenchmarkMutexLock|BenchmarkSyncBufferPool)$' ./pools ./sync`,
+		Command(Filter(entries, []string{"concurrency"})))
+	assert.Equal(t, []string{"a", "b"}, ParseTags(" a,,b "))
+}
+
+func TestRepositoryCatalog(t *testing.T) {
+	entries, err := Load("../../catalog.json")
+	assert.NoError(t, err)
+	files, err := bench.Discover("../..")
+	assert.NoError(t, err)
+	assert.Empty(t, Check(entries, files))
+}
@@ -0,0 +1,163 @@
+// Package claims checks the conclusions drawn in the README against fresh
+// benchmark results.
+package claims
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+)
+
+// Relation is the expected relation between the two sides of a claim.
+type Relation string
+
+const (
+	// Faster claims that A is faster than B by at least the claim's ratio,
+	// i.e. B's median ns/op is at least Ratio times A's.
+	Faster Relation = "faster"
+	// Similar claims that neither side is faster than the other by more than
+	// the claim's ratio.
+	Similar Relation = "similar"
+)
+
+// Claim is a single conclusion drawn from a pair of benchmarks.
+type Claim struct {
+	ID       string   `json:"id"`
+	File     string   `json:"file"`
+	Text     string   `json:"claim"`
+	A        string   `json:"a"`
+	B        string   `json:"b"`
+	Relation Relation `json:"relation"`
+	Ratio    float64  `json:"ratio"`
+}
+
+// Pair returns the variant pair measured by the claim.
+func (c Claim) Pair() compare.Pair {
+	return compare.Pair{File: c.File, A: c.A, B: c.B}
+}
+
+// Load reads the claims declared in the JSON file at path, with the file of
+// each claim as declared.
+func Load(path string) ([]Claim, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cs []Claim
+	if err := json.Unmarshal(data, &cs); err != nil {
+		return nil, fmt.Errorf("claims: %s: %v", path, err)
+	}
+	for _, c := range cs {
+		if c.Relation != Faster && c.Relation != Similar {
+			return nil, fmt.Errorf("claims: %s: claim %q has unknown relation %q", path, c.ID, c.Relation)
+		}
+		if c.Ratio < 1 {
+			return nil, fmt.Errorf("claims: %s: claim %q has ratio %v, want at least 1", path, c.ID, c.Ratio)
+		}
+	}
+	return cs, nil
+}
+
+// FileName is the name of the file declaring the claims about the
+// benchmarks of a package, next to them in the package's directory.
+const FileName = "claims.json"
+
+// LoadAll reads the claims declared next to the benchmarks of every package
+// under root. The file of each claim is declared relative to its package and
+// returned relative to root, e.g. sync/defer_test.go.
+func LoadAll(root string) ([]Claim, error) {
+	pkgs, err := bench.Packages(root)
+	if err != nil {
+		return nil, err
+	}
+	var all []Claim
+	for _, pkg := range pkgs {
+		cs, err := Load(filepath.Join(root, filepath.FromSlash(pkg), FileName))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i := range cs {
+			cs[i].File = path.Join(pkg, cs[i].File)
+		}
+		all = append(all, cs...)
+	}
+	return all, nil
+}
+
+// Status is the outcome of checking a claim.
+type Status string
+
+const (
+	// Holds means the claim still holds.
+	Holds Status = "holds"
+	// Weakened means A is still faster but not by the claimed ratio, or not
+	// significantly.
+	Weakened Status = "weakened"
+	// Flipped means the results contradict the claim.
+	Flipped Status = "flipped"
+	// Missing means one of the benchmarks did not report any results.
+	Missing Status = "missing"
+)
+
+// Outcome is the result of checking a single claim.
+type Outcome struct {
+	Claim
+	Comparison compare.Comparison
+	// Observed is the ratio of B's median to A's.
+	Observed float64
+	Status   Status
+}
+
+// Check compares both sides of every claim using the samples in set.
+func Check(set *bench.Set, cs []Claim, opts compare.Options) []Outcome {
+	pairs := make([]compare.Pair, len(cs))
+	for i, c := range cs {
+		pairs[i] = c.Pair()
+	}
+	comparisons := compare.Compare(set, pairs, opts)
+
+	outcomes := make([]Outcome, len(cs))
+	for i, c := range cs {
+		cmp := comparisons[i]
+		o := Outcome{Claim: c, Comparison: cmp, Observed: math.NaN()}
+		if cmp.A.N == 0 || cmp.B.N == 0 {
+			o.Status = Missing
+			outcomes[i] = o
+			continue
+		}
+		o.Observed = cmp.B.Median / cmp.A.Median
+		o.Status = status(c, o.Observed, cmp.Significant)
+		outcomes[i] = o
+	}
+	return outcomes
+}
+
+func status(c Claim, observed float64, significant bool) Status {
+	switch c.Relation {
+	case Similar:
+		spread := math.Max(observed, 1/observed)
+		if significant && spread > c.Ratio {
+			return Flipped
+		}
+		return Holds
+	default:
+		switch {
+		case significant && observed >= c.Ratio:
+			return Holds
+		case significant && observed < 1:
+			return Flipped
+		default:
+			return Weakened
+		}
+	}
+}
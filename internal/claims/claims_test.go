@@ -0,0 +1,37 @@
+package claims
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	faster := Claim{Relation: Faster, Ratio: 2}
+	assert.Equal(t, Holds, status(faster, 3.5, true))
+	assert.Equal(t, Weakened, status(faster, 1.5, true))
+	assert.Equal(t, Weakened, status(faster, 3.5, false))
+	assert.Equal(t, Flipped, status(faster, 0.8, true))
+
+	similar := Claim{Relation: Similar, Ratio: 1.2}
+	assert.Equal(t, Holds, status(similar, 1.1, true))
+	assert.Equal(t, Holds, status(similar, 0.5, false))
+	assert.Equal(t, Flipped, status(similar, 0.5, true))
+}
+
+func TestLoadAll(t *testing.T) {
+	cs, err := LoadAll("../..")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cs)
+
+	ids := make(map[string]bool)
+	for _, c := range cs {
+		assert.False(t, ids[c.ID], "claim %q is declared twice", c.ID)
+		ids[c.ID] = true
+		_, err := os.Stat(filepath.Join("../..", filepath.FromSlash(c.File)))
+		assert.NoError(t, err, "claim %q", c.ID)
+	}
+	assert.True(t, ids["defer-cost"])
+}
@@ -0,0 +1,175 @@
+// Package compare performs statistical comparisons between repeated runs of
+// pairs of benchmark variants.
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+)
+
+// Pair declares two variants of the same operation which should be compared,
+// e.g. BenchmarkMutexUnlock and BenchmarkMutexDeferUnlock.
+type Pair struct {
+	// File is the source file declaring both benchmarks, relative to the
+	// root of the repository, e.g. sync/defer_test.go.
+	File string `json:"file"`
+	A    string `json:"a"`
+	B    string `json:"b"`
+}
+
+// LoadPairs reads the pairs declared in the JSON file at path.
+func LoadPairs(path string) ([]Pair, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pairs []Pair
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, fmt.Errorf("compare: %s: %v", path, err)
+	}
+	return pairs, nil
+}
+
+// TopLevel returns the names of the top-level benchmarks which must be run
+// to measure the given pairs, suitable for passing to bench.Regexp.
+func TopLevel(pairs []Pair) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range pairs {
+		for _, name := range []string{p.A, p.B} {
+			if i := strings.IndexByte(name, '/'); i >= 0 {
+				name = name[:i]
+			}
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Packages returns the directories of the packages declaring pairs, relative
+// to the root of the repository, suitable for bench.Options.Packages.
+func Packages(pairs []Pair) []string {
+	var pkgs []string
+	seen := make(map[string]bool)
+	for _, p := range pairs {
+		if pkg := path.Dir(p.File); !seen[pkg] {
+			seen[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// Samples returns the ns/op measurements of every result named name in set.
+func Samples(set *bench.Set, name string) Sample {
+	var s Sample
+	for _, r := range set.Lookup(name) {
+		s = append(s, r.NsPerOp)
+	}
+	return s
+}
+
+// Summary describes the sample of a single benchmark.
+type Summary struct {
+	Name   string
+	N      int
+	Median float64
+	Lo, Hi float64
+}
+
+// Summarize computes the median of s and its confidence interval.
+func Summarize(name string, s Sample, confidence float64) Summary {
+	lo, hi := s.MedianCI(confidence)
+	return Summary{Name: name, N: len(s), Median: s.Median(), Lo: lo, Hi: hi}
+}
+
+// Comparison is the result of comparing the two variants of a pair.
+type Comparison struct {
+	Pair
+	A, B Summary
+	// Delta is the relative difference of the medians, (B - A) / B. It is
+	// positive when A is faster than B.
+	Delta float64
+	P     float64
+	// Significant reports whether P is below the significance level.
+	Significant bool
+}
+
+// Verdict returns a human readable description of the comparison.
+func (c Comparison) Verdict() string {
+	switch {
+	case c.A.N == 0 || c.B.N == 0:
+		return "missing samples"
+	case !c.Significant:
+		return fmt.Sprintf("no significant difference (p=%.3f)", c.P)
+	case c.Delta >= 0:
+		return fmt.Sprintf("%s is %.1f%% faster than %s (p=%.3f)", c.A.Name, 100*c.Delta, c.B.Name, c.P)
+	default:
+		return fmt.Sprintf("%s is %.1f%% faster than %s (p=%.3f)", c.B.Name, 100*-c.Delta/(1-c.Delta), c.A.Name, c.P)
+	}
+}
+
+// Options configures a comparison.
+type Options struct {
+	// Confidence is the confidence level of the median intervals.
+	Confidence float64
+	// Alpha is the significance level of the Mann-Whitney U test.
+	Alpha float64
+}
+
+// DefaultOptions are the options used if none are given.
+var DefaultOptions = Options{Confidence: 0.95, Alpha: 0.05}
+
+// Compare compares every pair using the samples in set.
+func Compare(set *bench.Set, pairs []Pair, opts Options) []Comparison {
+	cs := make([]Comparison, 0, len(pairs))
+	for _, p := range pairs {
+		a, b := Samples(set, p.A), Samples(set, p.B)
+		c := Comparison{
+			Pair: p,
+			A:    Summarize(p.A, a, opts.Confidence),
+			B:    Summarize(p.B, b, opts.Confidence),
+			P:    math.NaN(),
+		}
+		if len(a) > 0 && len(b) > 0 {
+			c.Delta = (c.B.Median - c.A.Median) / c.B.Median
+			_, c.P = MannWhitneyU(a, b)
+			c.Significant = c.P < opts.Alpha
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+// WriteTable writes the comparisons as an aligned text table.
+func WriteTable(w io.Writer, cs []Comparison, confidence float64) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	ci := fmt.Sprintf("%.0f%% CI", 100*confidence)
+	fmt.Fprintf(tw, "A\tmedian (%s)\tB\tmedian (%s)\tverdict\n", ci, ci)
+	for _, c := range cs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			c.A.Name, formatSummary(c.A), c.B.Name, formatSummary(c.B), c.Verdict())
+	}
+	return tw.Flush()
+}
+
+func formatSummary(s Summary) string {
+	if s.N == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s ns/op [%s, %s] n=%d",
+		bench.FormatValue(s.Median), bench.FormatValue(s.Lo), bench.FormatValue(s.Hi), s.N)
+}
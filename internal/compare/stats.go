@@ -0,0 +1,230 @@
+package compare
+
+import (
+	"math"
+	"sort"
+)
+
+// Sample is a set of repeated measurements of a single benchmark.
+type Sample []float64
+
+// sorted returns a sorted copy of s.
+func (s Sample) sorted() Sample {
+	c := make(Sample, len(s))
+	copy(c, s)
+	sort.Float64s(c)
+	return c
+}
+
+// Median returns the median of s, or NaN if s is empty.
+func (s Sample) Median() float64 {
+	if len(s) == 0 {
+		return math.NaN()
+	}
+	c := s.sorted()
+	n := len(c)
+	if n%2 == 1 {
+		return c[n/2]
+	}
+	return (c[n/2-1] + c[n/2]) / 2
+}
+
+// Mean returns the arithmetic mean of s, or NaN if s is empty.
+func (s Sample) Mean() float64 {
+	if len(s) == 0 {
+		return math.NaN()
+	}
+	var sum float64
+	for _, v := range s {
+		sum += v
+	}
+	return sum / float64(len(s))
+}
+
+// CV returns the coefficient of variation of s, its sample standard
+// deviation relative to its mean, or NaN if s has fewer than two values.
+func (s Sample) CV() float64 {
+	if len(s) < 2 {
+		return math.NaN()
+	}
+	mean := s.Mean()
+	var ss float64
+	for _, v := range s {
+		ss += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(ss/float64(len(s)-1)) / mean
+}
+
+// MedianCI returns a distribution-free confidence interval for the median of
+// s at the given confidence level, e.g. 0.95. The bounds are order
+// statistics of s chosen using the binomial distribution. If s is too small
+// to achieve the requested confidence, the minimum and maximum of s are
+// returned instead.
+func (s Sample) MedianCI(confidence float64) (lo, hi float64) {
+	if len(s) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	c := s.sorted()
+	n := len(c)
+
+	// Find the largest k such that P(X < k) <= (1-confidence)/2 for
+	// X ~ Binomial(n, 1/2). The interval [c[k-1], c[n-k]] then covers the
+	// median with at least the requested confidence.
+	alpha := (1 - confidence) / 2
+	k, cdf := 0, 0.0
+	for i := 0; i < n; i++ {
+		p := binomialPMF(n, i)
+		if cdf+p > alpha {
+			break
+		}
+		cdf += p
+		k = i + 1
+	}
+	if k == 0 {
+		return c[0], c[n-1]
+	}
+	return c[k-1], c[n-k]
+}
+
+// binomialPMF returns P(X = k) for X ~ Binomial(n, 1/2).
+func binomialPMF(n, k int) float64 {
+	lg := func(x int) float64 {
+		v, _ := math.Lgamma(float64(x + 1))
+		return v
+	}
+	return math.Exp(lg(n) - lg(k) - lg(n-k) - float64(n)*math.Ln2)
+}
+
+// MannWhitneyU performs a two-sided Mann-Whitney U test of the null
+// hypothesis that a and b are drawn from the same distribution. It returns
+// the U statistic for a and the p-value. The exact distribution of U is used
+// for small samples without ties, otherwise the normal approximation with a
+// tie correction is used.
+func MannWhitneyU(a, b Sample) (u, p float64) {
+	n1, n2 := len(a), len(b)
+	if n1 == 0 || n2 == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	ranks, ties := rank(a, b)
+	var r1 float64
+	for _, r := range ranks[:n1] {
+		r1 += r
+	}
+	u = r1 - float64(n1*(n1+1))/2
+
+	if !ties && n1 <= 50 && n2 <= 50 {
+		return u, exactP(n1, n2, u)
+	}
+
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	// The tie correction subtracts sum(t^3 - t) over groups of tied ranks.
+	variance := float64(n1*n2) / 12 * (n + 1 - tieCorrection(a, b)/(n*(n-1)))
+	if variance == 0 {
+		return u, 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	p = math.Erfc(z / math.Sqrt2)
+	return u, math.Min(p, 1)
+}
+
+// rank returns the ranks of the concatenation of a and b, averaging the
+// ranks of tied values, and whether any ties were found.
+func rank(a, b Sample) ([]float64, bool) {
+	type obs struct {
+		v float64
+		i int
+	}
+	all := make([]obs, 0, len(a)+len(b))
+	for i, v := range a {
+		all = append(all, obs{v, i})
+	}
+	for i, v := range b {
+		all = append(all, obs{v, len(a) + i})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	ranks := make([]float64, len(all))
+	ties := false
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		if j-i > 1 {
+			ties = true
+		}
+		r := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			ranks[all[k].i] = r
+		}
+		i = j
+	}
+	return ranks, ties
+}
+
+// tieCorrection returns sum(t^3 - t) over the groups of tied values in the
+// concatenation of a and b.
+func tieCorrection(a, b Sample) float64 {
+	counts := make(map[float64]int)
+	for _, v := range a {
+		counts[v]++
+	}
+	for _, v := range b {
+		counts[v]++
+	}
+	var sum float64
+	for _, t := range counts {
+		sum += float64(t*t*t - t)
+	}
+	return sum
+}
+
+// exactP returns the two-sided p-value of u under the exact distribution of
+// the U statistic for samples of size n1 and n2 without ties.
+func exactP(n1, n2 int, u float64) float64 {
+	// counts[i][j][k] is the number of arrangements of i values from the
+	// first sample and j from the second with U = k. Only the current and
+	// previous i are kept.
+	max := n1 * n2
+	prev := make([][]float64, n2+1)
+	for j := range prev {
+		prev[j] = make([]float64, max+1)
+		prev[j][0] = 1
+	}
+	for i := 1; i <= n1; i++ {
+		cur := make([][]float64, n2+1)
+		cur[0] = make([]float64, max+1)
+		cur[0][0] = 1
+		for j := 1; j <= n2; j++ {
+			cur[j] = make([]float64, max+1)
+			for k := 0; k <= i*j; k++ {
+				// The largest value either comes from the first sample, in
+				// which case it exceeds all j values of the second, or from
+				// the second sample.
+				if k >= j {
+					cur[j][k] += prev[j][k-j]
+				}
+				cur[j][k] += cur[j-1][k]
+			}
+		}
+		prev = cur
+	}
+
+	dist := prev[n2]
+	var total, lower, upper float64
+	for k, c := range dist {
+		total += c
+		if float64(k) <= u {
+			lower += c
+		}
+		if float64(k) >= u {
+			upper += c
+		}
+	}
+	return math.Min(1, 2*math.Min(lower, upper)/total)
+}
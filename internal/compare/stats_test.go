@@ -0,0 +1,45 @@
+package compare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 3.0, Sample{5, 1, 3}.Median())
+	assert.Equal(t, 2.5, Sample{4, 1, 3, 2}.Median())
+}
+
+func TestCV(t *testing.T) {
+	assert.Equal(t, 2.5, Sample{1, 2, 3, 4}.Mean())
+	assert.InDelta(t, 0.5164, Sample{1, 2, 3, 4}.CV(), 1e-4)
+	assert.Equal(t, 0.0, Sample{7, 7, 7}.CV())
+	assert.True(t, math.IsNaN(Sample{1}.CV()))
+}
+
+func TestMedianCI(t *testing.T) {
+	s := Sample{10, 1, 9, 2, 8, 3, 7, 4, 6, 5}
+	lo, hi := s.MedianCI(0.95)
+	assert.Equal(t, 2.0, lo)
+	assert.Equal(t, 9.0, hi)
+
+	lo, hi = Sample{2, 1, 3}.MedianCI(0.95)
+	assert.Equal(t, 1.0, lo)
+	assert.Equal(t, 3.0, hi)
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	u, p := MannWhitneyU(Sample{1, 2, 3, 4, 5}, Sample{6, 7, 8, 9, 10})
+	assert.Equal(t, 0.0, u)
+	assert.InDelta(t, 2.0/252, p, 1e-9)
+
+	_, p = MannWhitneyU(Sample{1, 3, 5, 7, 9}, Sample{2, 4, 6, 8, 10})
+	assert.True(t, p > 0.5)
+
+	// Ties force the normal approximation.
+	u, p = MannWhitneyU(Sample{1, 1, 2, 2}, Sample{3, 3, 4, 4})
+	assert.Equal(t, 0.0, u)
+	assert.True(t, p < 0.05)
+}
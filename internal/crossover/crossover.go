@@ -0,0 +1,120 @@
+// Package crossover ranks several implementations of the same operation at
+// each input size of a sweep, showing the sizes at which the fastest one
+// changes, e.g. from the hash with the least setup cost for 8 byte keys to
+// the one with the highest throughput for 1M blobs.
+package crossover
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+)
+
+// Entry is the median ns/op of one implementation at one size.
+type Entry struct {
+	Name    string
+	NsPerOp float64
+}
+
+// Row ranks the implementations at a single size.
+type Row struct {
+	Size int
+	// Ranked is ordered from fastest to slowest.
+	Ranked []Entry
+}
+
+// GBPerSec returns the throughput of the i'th fastest implementation in
+// gigabytes per second.
+func (r *Row) GBPerSec(i int) float64 {
+	return float64(r.Size) / r.Ranked[i].NsPerOp
+}
+
+// Build groups the results in set matching pattern by size and ranks them.
+// Pattern must have a "series" subexpression naming the implementation and
+// a "size" subexpression, such as 64, 4K or 1M, for example
+// BenchmarkHashSize/farm64/size=4K. The rows are ordered by size and results
+// sampled several times are reduced to their median.
+func Build(set *bench.Set, pattern *regexp.Regexp) ([]*Row, error) {
+	si, zi := pattern.SubexpIndex("series"), pattern.SubexpIndex("size")
+	if si < 0 || zi < 0 {
+		return nil, fmt.Errorf("crossover: pattern %s needs series and size subexpressions", pattern)
+	}
+	bySize := make(map[int]*Row)
+	seen := make(map[string]bool)
+	for _, r := range set.Results {
+		m := pattern.FindStringSubmatch(r.Name)
+		if m == nil || seen[r.Name] || r.NsPerOp <= 0 {
+			continue
+		}
+		seen[r.Name] = true
+		size, err := bench.ParseSize(m[zi])
+		if err != nil {
+			return nil, fmt.Errorf("crossover: %s: %v", r.Name, err)
+		}
+		row := bySize[size]
+		if row == nil {
+			row = &Row{Size: size}
+			bySize[size] = row
+		}
+		row.Ranked = append(row.Ranked, Entry{Name: m[si], NsPerOp: compare.Samples(set, r.Name).Median()})
+	}
+
+	rows := make([]*Row, 0, len(bySize))
+	for _, row := range bySize {
+		sort.SliceStable(row.Ranked, func(i, j int) bool {
+			a, b := row.Ranked[i], row.Ranked[j]
+			if a.NsPerOp != b.NsPerOp {
+				return a.NsPerOp < b.NsPerOp
+			}
+			return a.Name < b.Name
+		})
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Size < rows[j].Size })
+	return rows, nil
+}
+
+// Crossovers describes each change of the fastest implementation between
+// consecutive sizes, e.g. "farm64 -> xxhash64 at 256".
+func Crossovers(rows []*Row) []string {
+	var out []string
+	for i := 1; i < len(rows); i++ {
+		prev, cur := rows[i-1].Ranked[0].Name, rows[i].Ranked[0].Name
+		if prev != cur {
+			out = append(out, fmt.Sprintf("%s -> %s at %s", prev, cur, bench.FormatSize(rows[i].Size)))
+		}
+	}
+	return out
+}
+
+// WriteTable writes the top fastest implementations at each size with their
+// throughput, followed by how much faster the winner is than the runner up.
+func WriteTable(w io.Writer, rows []*Row, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "size\t")
+	for i := 1; i <= top; i++ {
+		fmt.Fprintf(tw, "#%d\tGB/s\t", i)
+	}
+	fmt.Fprintf(tw, "lead\n")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t", bench.FormatSize(r.Size))
+		for i := 0; i < top; i++ {
+			if i < len(r.Ranked) {
+				fmt.Fprintf(tw, "%s\t%s\t", r.Ranked[i].Name, bench.FormatValue(r.GBPerSec(i)))
+			} else {
+				fmt.Fprintf(tw, "-\t-\t")
+			}
+		}
+		if len(r.Ranked) > 1 {
+			fmt.Fprintf(tw, "%.2fx\n", r.Ranked[1].NsPerOp/r.Ranked[0].NsPerOp)
+		} else {
+			fmt.Fprintf(tw, "-\n")
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,44 @@
+package crossover
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/stretchr/testify/assert"
+)
+
+var pattern = regexp.MustCompile(`^BenchmarkHashSize/(?P<series>\w+)/size=(?P<size>\d+[KM]?)$`)
+
+func TestBuild(t *testing.T) {
+	set := &bench.Set{Results: []*bench.Result{
+		{Name: "BenchmarkHashSize/farm64/size=8", NsPerOp: 4},
+		{Name: "BenchmarkHashSize/farm64/size=1K", NsPerOp: 100},
+		{Name: "BenchmarkHashSize/xxhash64/size=8", NsPerOp: 6},
+		{Name: "BenchmarkHashSize/xxhash64/size=8", NsPerOp: 8},
+		{Name: "BenchmarkHashSize/xxhash64/size=1K", NsPerOp: 64},
+		{Name: "BenchmarkHash64Xxhash", NsPerOp: 10},
+	}}
+	rows, err := Build(set, pattern)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	assert.Equal(t, 8, rows[0].Size)
+	assert.Equal(t, []Entry{{"farm64", 4}, {"xxhash64", 7}}, rows[0].Ranked)
+	assert.Equal(t, 1024, rows[1].Size)
+	assert.Equal(t, "xxhash64", rows[1].Ranked[0].Name)
+	assert.InDelta(t, 16, rows[1].GBPerSec(0), 1e-9)
+	assert.Equal(t, []string{"farm64 -> xxhash64 at 1K"}, Crossovers(rows))
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteTable(&buf, rows, 3))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "1.75x")
+	assert.Contains(t, lines[2], "1K")
+
+	_, err = Build(set, regexp.MustCompile(`^BenchmarkHashSize/`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,311 @@
+// Package dce finds benchmark loops whose bodies the compiler has optimized
+// away.
+//
+// A benchmark which computes a result and then drops it measures nothing if
+// the compiler can prove the work has no effect. The loops are found in the
+// source and a loop is reported as empty if none of the instructions in the
+// disassembly of the compiled test binary come from the statements in its
+// body.
+package dce
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+)
+
+// Loop is a for statement in a benchmark function, including any closures
+// the benchmark declares.
+type Loop struct {
+	File      string
+	Benchmark string
+	// Line is the line of the for keyword, which the compiler attributes
+	// the loop condition and increment to.
+	Line int
+	// First and Last are the lines spanned by the statements in the body.
+	First, Last int
+	// Hot reports whether this is the benchmark loop itself, i.e. one
+	// which runs while i < b.N or pb.Next().
+	Hot bool
+}
+
+// Loops returns the loops with a non-empty body in every benchmark in the
+// _test.go files in dir, sorted by file and line.
+func Loops(dir string) ([]Loop, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var loops []Loop
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(path)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !bench.IsBenchmark(fn) || fn.Body == nil {
+				continue
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				var (
+					body *ast.BlockStmt
+					hot  bool
+				)
+				switch s := n.(type) {
+				case *ast.ForStmt:
+					body, hot = s.Body, isBenchmarkLoop(s)
+				case *ast.RangeStmt:
+					body = s.Body
+				default:
+					return true
+				}
+				if len(body.List) == 0 {
+					return true
+				}
+				loops = append(loops, Loop{
+					File:      name,
+					Benchmark: fn.Name.Name,
+					Line:      fset.Position(n.Pos()).Line,
+					First:     fset.Position(body.List[0].Pos()).Line,
+					Last:      fset.Position(body.List[len(body.List)-1].End()).Line,
+					Hot:       hot,
+				})
+				return true
+			})
+		}
+	}
+	return loops, nil
+}
+
+// isBenchmarkLoop reports whether the condition of s is i < b.N or
+// pb.Next().
+func isBenchmarkLoop(s *ast.ForStmt) bool {
+	hot := false
+	if s.Cond == nil {
+		return false
+	}
+	ast.Inspect(s.Cond, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			hot = hot || n.Sel.Name == "N"
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Next" && len(n.Args) == 0 {
+				hot = true
+			}
+		}
+		return !hot
+	})
+	return hot
+}
+
+// Disassemble compiles the test binary for the package in dir and returns
+// the disassembly of its benchmarks and their closures.
+func Disassemble(dir string) ([]Func, error) {
+	tmp, err := ioutil.TempDir("", "dce")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	bin := filepath.Join(tmp, "bench.test")
+
+	build := exec.Command("go", "test", "-c", "-o", bin, ".")
+	build.Dir = dir
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return nil, fmt.Errorf("compiling test binary: %v", err)
+	}
+
+	var out bytes.Buffer
+	objdump := exec.Command("go", "tool", "objdump", "-s", `\.Benchmark`, bin)
+	objdump.Stdout = &out
+	objdump.Stderr = os.Stderr
+	if err := objdump.Run(); err != nil {
+		return nil, fmt.Errorf("disassembling test binary: %v", err)
+	}
+	return ParseObjdump(&out)
+}
+
+// Inst is a single instruction in the output of go tool objdump.
+type Inst struct {
+	File string
+	Line int
+	Addr uint64
+	Asm  string
+}
+
+// Func is the disassembly of a function.
+type Func struct {
+	Name  string
+	Insts []Inst
+}
+
+// ParseObjdump parses the output of go tool objdump.
+func ParseObjdump(r io.Reader) ([]Func, error) {
+	var funcs []Func
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, "TEXT ") {
+			name := strings.Fields(line)[1]
+			funcs = append(funcs, Func{Name: strings.TrimSuffix(name, "(SB)")})
+			continue
+		}
+		if len(funcs) == 0 || !strings.HasPrefix(line, "  ") {
+			continue
+		}
+		inst, ok := parseInst(line)
+		if !ok {
+			continue
+		}
+		f := &funcs[len(funcs)-1]
+		f.Insts = append(f.Insts, inst)
+	}
+	return funcs, s.Err()
+}
+
+// parseInst parses a line of the form
+//
+//	file.go:12	0x4f3a20		488b4c2410		MOVQ 0x10(SP), CX
+func parseInst(line string) (Inst, bool) {
+	fields := strings.FieldsFunc(line, func(r rune) bool { return r == '\t' })
+	var parts []string
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			parts = append(parts, f)
+		}
+	}
+	if len(parts) < 4 {
+		return Inst{}, false
+	}
+	colon := strings.LastIndexByte(parts[0], ':')
+	if colon < 0 {
+		return Inst{}, false
+	}
+	lineNo, err := strconv.Atoi(parts[0][colon+1:])
+	if err != nil {
+		return Inst{}, false
+	}
+	addr, err := strconv.ParseUint(strings.TrimPrefix(parts[1], "0x"), 16, 64)
+	if err != nil {
+		return Inst{}, false
+	}
+	return Inst{
+		File: parts[0][:colon],
+		Line: lineNo,
+		Addr: addr,
+		Asm:  strings.Join(parts[3:], " "),
+	}, true
+}
+
+// Status is the outcome of checking a loop.
+type Status int
+
+const (
+	// OK means instructions were compiled from the loop body.
+	OK Status = iota
+	// Empty means the benchmark was compiled but none of its instructions
+	// come from the loop body.
+	Empty
+	// NotFound means the benchmark isn't in the disassembly.
+	NotFound
+)
+
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Empty:
+		return "empty"
+	default:
+		return "not found"
+	}
+}
+
+// Finding is the outcome of checking a loop against the disassembly.
+type Finding struct {
+	Loop
+	Status Status
+	// Insts is the number of instructions compiled from the loop body.
+	Insts int
+}
+
+// Check reports whether the body of each loop survived compilation into the
+// disassembled funcs, which should include the benchmarks and their
+// closures. Every loop in loops gets a finding, in the same order.
+//
+// The compiler attributes an instruction to the innermost source position it
+// was compiled from, so an intrinsic or a call in the body is attributed to
+// the line of the call while the loop's condition and increment, including
+// pb.Next and b.N when inlined, are attributed to the for statement or to
+// package testing.
+func Check(loops []Loop, funcs []Func) []Finding {
+	byBenchmark := make(map[string][]Func)
+	for _, f := range funcs {
+		name := Benchmark(f.Name)
+		byBenchmark[name] = append(byBenchmark[name], f)
+	}
+
+	findings := make([]Finding, len(loops))
+	for i, l := range loops {
+		fd := Finding{Loop: l, Status: NotFound}
+		for _, f := range byBenchmark[l.Benchmark] {
+			fd.Status = Empty
+			for _, inst := range f.Insts {
+				if inst.File != l.File {
+					continue
+				}
+				if inst.Line >= l.First && inst.Line <= l.Last || inst.Line == l.Line && IsWork(inst) {
+					fd.Insts++
+				}
+			}
+		}
+		if fd.Insts > 0 {
+			fd.Status = OK
+		}
+		findings[i] = fd
+	}
+	return findings
+}
+
+// IsWork reports whether inst, attributed to the line of a for statement,
+// does the work of the loop rather than advancing it. The compiler replaces
+// some loops with a call, e.g. a loop zeroing a slice with a call to
+// runtime.memclrNoHeapPointers, which it attributes to the for statement.
+// Calls to package testing, such as pb.Next when not inlined, are part of the
+// benchmark loop itself.
+func IsWork(inst Inst) bool {
+	return strings.HasPrefix(inst.Asm, "CALL ") && !strings.HasPrefix(inst.Asm, "CALL testing.")
+}
+
+// Benchmark returns the name of the benchmark a symbol belongs to, e.g.
+// BenchmarkFoo for both example.com/pkg.BenchmarkFoo and
+// example.com/pkg.BenchmarkFoo.func1.
+func Benchmark(symbol string) string {
+	if i := strings.LastIndexByte(symbol, '/'); i >= 0 {
+		symbol = symbol[i+1:]
+	}
+	parts := strings.Split(symbol, ".")
+	if len(parts) < 2 {
+		return symbol
+	}
+	return parts[1]
+}
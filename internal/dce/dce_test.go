@@ -0,0 +1,58 @@
+package dce
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleObjdump = `TEXT example.com/pkg.BenchmarkKept(SB) /src/pkg/x_test.go
+  x_test.go:10		0x1000			31c0			XORL AX, AX
+  x_test.go:10		0x1002			eb05			JMP 0x1009
+  x_test.go:11		0x1004			e800000000		CALL example.com/pkg.work(SB)
+  x_test.go:10		0x1009			48ffc0			INCQ AX
+  x_test.go:10		0x100c			7ef2			JLE 0x1004
+TEXT example.com/pkg.BenchmarkGone(SB) /src/pkg/x_test.go
+  x_test.go:20		0x2000			31c0			XORL AX, AX
+  x_test.go:20		0x2002			48ffc0			INCQ AX
+  x_test.go:20		0x2005			7efb			JLE 0x2002
+TEXT example.com/pkg.BenchmarkClear.func1(SB) /src/pkg/x_test.go
+  x_test.go:30		0x3000			31d2			XORL DX, DX
+  x_test.go:31		0x3002			e800000000		CALL runtime.memclrNoHeapPointers(SB)
+  benchmark.go:923	0x3007			7ef7			JNE 0x3000
+`
+
+func TestParseObjdump(t *testing.T) {
+	funcs, err := ParseObjdump(strings.NewReader(sampleObjdump))
+	assert.NoError(t, err)
+	assert.Len(t, funcs, 3)
+	assert.Equal(t, "example.com/pkg.BenchmarkKept", funcs[0].Name)
+	assert.Len(t, funcs[0].Insts, 5)
+	assert.Equal(t, Inst{File: "x_test.go", Line: 11, Addr: 0x1004, Asm: "CALL example.com/pkg.work(SB)"}, funcs[0].Insts[2])
+	assert.Equal(t, "benchmark.go", funcs[2].Insts[2].File)
+}
+
+func TestCheck(t *testing.T) {
+	funcs, err := ParseObjdump(strings.NewReader(sampleObjdump))
+	assert.NoError(t, err)
+	loops := []Loop{
+		{File: "x_test.go", Benchmark: "BenchmarkKept", Line: 10, First: 11, Last: 11},
+		{File: "x_test.go", Benchmark: "BenchmarkGone", Line: 20, First: 21, Last: 21},
+		{File: "x_test.go", Benchmark: "BenchmarkClear", Line: 31, First: 32, Last: 32},
+		{File: "x_test.go", Benchmark: "BenchmarkMissing", Line: 40, First: 41, Last: 41},
+	}
+	findings := Check(loops, funcs)
+	assert.Len(t, findings, 4)
+	assert.Equal(t, OK, findings[0].Status)
+	assert.Equal(t, 1, findings[0].Insts)
+	assert.Equal(t, Empty, findings[1].Status)
+	assert.Equal(t, OK, findings[2].Status)
+	assert.Equal(t, NotFound, findings[3].Status)
+}
+
+func TestBenchmark(t *testing.T) {
+	assert.Equal(t, "BenchmarkFoo", Benchmark("example.com/pkg.BenchmarkFoo"))
+	assert.Equal(t, "BenchmarkFoo", Benchmark("example.com/pkg.BenchmarkFoo.func1.2"))
+	assert.Equal(t, "BenchmarkFoo", Benchmark("main.BenchmarkFoo"))
+}
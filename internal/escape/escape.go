@@ -0,0 +1,428 @@
+// Package escape parses the escape analysis and inlining decisions the
+// compiler prints with -gcflags=-m=2 and attributes them to the benchmarks
+// and the helpers they call.
+package escape
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+)
+
+// Kind is the kind of a diagnostic.
+type Kind int
+
+const (
+	// CanInline is reported at the declaration of a function which can
+	// be inlined, CannotInline at one which can't, with the reason.
+	CanInline Kind = iota
+	CannotInline
+	// Inlined is reported at a call which was inlined.
+	Inlined
+	// Escapes is reported at an allocation which escapes to the heap and
+	// MovedToHeap at a variable which does.
+	Escapes
+	MovedToHeap
+	// NoEscape is reported at a value which stays on the stack.
+	NoEscape
+	// Leak is reported at a parameter which outlives the call.
+	Leak
+)
+
+var kindNames = [...]string{
+	CanInline:    "can inline",
+	CannotInline: "cannot inline",
+	Inlined:      "inlined",
+	Escapes:      "escapes",
+	MovedToHeap:  "moved to heap",
+	NoEscape:     "does not escape",
+	Leak:         "leaking param",
+}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// MarshalText implements encoding.TextMarshaler so kinds are readable in
+// JSON.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *Kind) UnmarshalText(text []byte) error {
+	for i, name := range kindNames {
+		if name == string(text) {
+			*k = Kind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("escape: unknown kind %q", text)
+}
+
+// Diag is a single diagnostic printed by the compiler.
+type Diag struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Col  int    `json:"col"`
+	Kind Kind   `json:"kind"`
+	// Subject is what the diagnostic is about, e.g. the expression which
+	// escapes or the function which was inlined.
+	Subject string `json:"subject"`
+	// Reason explains why a function can't be inlined.
+	Reason string `json:"reason,omitempty"`
+	// Func is the function declaration the diagnostic is in.
+	Func string `json:"func,omitempty"`
+}
+
+var (
+	diagRE       = regexp.MustCompile(`^(.+\.go):(\d+):(\d+): (.*)$`)
+	canInlineRE  = regexp.MustCompile(`^can inline (\S+)`)
+	cannotRE     = regexp.MustCompile(`^cannot inline (\S+): (.*)$`)
+	inlinedRE    = regexp.MustCompile(`^inlining call to (\S+)`)
+	escapesRE    = regexp.MustCompile(`^(.+) escapes to heap$`)
+	movedRE      = regexp.MustCompile(`^moved to heap: (\S+)$`)
+	noEscapeRE   = regexp.MustCompile(`^(.+) does not escape$`)
+	leakRE       = regexp.MustCompile(`^leaking param(?: content)?: (\S+)$`)
+	diagPatterns = []struct {
+		re   *regexp.Regexp
+		kind Kind
+	}{
+		{canInlineRE, CanInline},
+		{cannotRE, CannotInline},
+		{inlinedRE, Inlined},
+		{escapesRE, Escapes},
+		{movedRE, MovedToHeap},
+		{noEscapeRE, NoEscape},
+		{leakRE, Leak},
+	}
+)
+
+// Parse parses the output of the compiler run with -m=2. The explanations -m=2
+// adds, i.e. the headers ending in a colon and the indented flow lines
+// below them, are skipped, as are repeated diagnostics.
+func Parse(r io.Reader) ([]Diag, error) {
+	var diags []Diag
+	seen := make(map[string]bool)
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		m := diagRE.FindStringSubmatch(s.Text())
+		if m == nil || seen[m[0]] {
+			continue
+		}
+		seen[m[0]] = true
+		msg := m[4]
+		if strings.HasPrefix(msg, " ") || strings.HasSuffix(msg, ":") {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		for _, p := range diagPatterns {
+			sm := p.re.FindStringSubmatch(msg)
+			if sm == nil {
+				continue
+			}
+			d := Diag{File: filepath.Base(m[1]), Line: line, Col: col, Kind: p.kind, Subject: sm[1]}
+			if p.kind == CannotInline {
+				d.Reason = sm[2]
+			}
+			diags = append(diags, d)
+			break
+		}
+	}
+	return diags, s.Err()
+}
+
+// Build compiles the test binary of the package in dir with -gcflags=-m=2
+// and returns the diagnostics.
+func Build(dir string) ([]Diag, error) {
+	var out bytes.Buffer
+	cmd := exec.Command("go", "test", "-c", "-o", os.DevNull, "-gcflags=-m=2", ".")
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go test -c -gcflags=-m=2: %v\n%s", err, out.Bytes())
+	}
+	return Parse(&out)
+}
+
+// Summary is what the compiler decided for a benchmark and the helpers it
+// calls.
+type Summary struct {
+	Benchmark string `json:"benchmark"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	// Helpers are the functions and methods declared in the package
+	// which the benchmark calls, directly or through other helpers.
+	Helpers []string `json:"helpers,omitempty"`
+	// Heap lists the allocations and variables which escape to the heap.
+	Heap []Diag `json:"heap,omitempty"`
+	// Inlined lists the calls which were inlined, by callee.
+	Inlined []string `json:"inlined,omitempty"`
+	// NotInlined lists the helpers and closures which can't be inlined.
+	NotInlined []Diag `json:"not_inlined,omitempty"`
+}
+
+// Load reads summaries written as JSON by cmd/escapes.
+func Load(path string) ([]Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var summaries []Summary
+	if err := json.NewDecoder(f).Decode(&summaries); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return summaries, nil
+}
+
+// funcDecl is a function declared in the package, by the name the compiler
+// uses for it, e.g. concat or (*ChannelBufferPool).Get.
+type funcDecl struct {
+	name       string
+	file       string
+	start, end int
+	decl       *ast.FuncDecl
+}
+
+// Summarize attributes diags to the function declarations of the package in
+// dir and returns a summary for every benchmark, sorted by file and line.
+func Summarize(dir string, diags []Diag) ([]Summary, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var (
+		decls []*funcDecl
+		files []*ast.File
+		lits  []*calledLit
+	)
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+		lits = append(lits, calledLits(fset, f, filepath.Base(path))...)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			decls = append(decls, &funcDecl{
+				name:  declName(fn),
+				file:  filepath.Base(path),
+				start: fset.Position(fn.Pos()).Line,
+				end:   fset.Position(fn.End()).Line,
+				decl:  fn,
+			})
+		}
+	}
+
+	// Calls are resolved to the functions they call with type information.
+	// Type checking errors, e.g. from a dependency which can't be loaded,
+	// only leave some calls unresolved so they are ignored.
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	if len(files) > 0 {
+		conf.Check(files[0].Name.Name, fset, files, info)
+	}
+	byObj := make(map[types.Object]*funcDecl)
+	for _, d := range decls {
+		if obj := info.Defs[d.decl.Name]; obj != nil {
+			byObj[obj] = d
+		}
+	}
+
+	for i := range diags {
+		for _, d := range decls {
+			if d.file == diags[i].File && diags[i].Line >= d.start && diags[i].Line <= d.end {
+				diags[i].Func = d.name
+				break
+			}
+		}
+	}
+
+	inlinedLits := inlinedLits(lits, diags)
+
+	var summaries []Summary
+	for _, d := range decls {
+		if !bench.IsBenchmark(d.decl) {
+			continue
+		}
+		helpers := callees(d, info, byObj)
+		funcs := map[string]bool{d.name: true}
+		s := Summary{Benchmark: d.name, File: d.file, Line: d.start}
+		for _, h := range helpers {
+			funcs[h.name] = true
+			s.Helpers = append(s.Helpers, h.name)
+		}
+		inlined := make(map[string]bool)
+		for _, diag := range diags {
+			if !funcs[diag.Func] {
+				continue
+			}
+			switch diag.Kind {
+			case Escapes, MovedToHeap:
+				if !inside(inlinedLits, diag) {
+					s.Heap = append(s.Heap, diag)
+				}
+			case Inlined:
+				if !inlined[diag.Subject] {
+					inlined[diag.Subject] = true
+					s.Inlined = append(s.Inlined, diag.Subject)
+				}
+			case CannotInline:
+				if diag.Subject != d.name {
+					s.NotInlined = append(s.NotInlined, diag)
+				}
+			}
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, nil
+}
+
+// calledLit is the position of a function literal which is called where it
+// is declared, e.g. func() *Foo { return new(Foo) }().
+type calledLit struct {
+	file                       string
+	line, col, endLine, endCol int
+}
+
+// calledLits returns the function literals in f which are called where they
+// are declared.
+func calledLits(fset *token.FileSet, f *ast.File, file string) []*calledLit {
+	var lits []*calledLit
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if lit, ok := call.Fun.(*ast.FuncLit); ok {
+			start, end := fset.Position(lit.Pos()), fset.Position(lit.End())
+			lits = append(lits, &calledLit{file, start.Line, start.Column, end.Line, end.Column})
+		}
+		return true
+	})
+	return lits
+}
+
+// inlinedLits returns the literals of lits whose call was inlined. The
+// compiler still reports the diagnostics of their bodies, e.g. an
+// allocation which escapes, although the body never runs, so they would be
+// counted twice alongside those of the inlined copy at the call.
+func inlinedLits(lits []*calledLit, diags []Diag) []*calledLit {
+	inlined := make(map[string]bool)
+	for _, d := range diags {
+		if d.Kind == Inlined {
+			inlined[d.Subject] = true
+		}
+	}
+	var found []*calledLit
+	for _, d := range diags {
+		if d.Kind != CanInline || !inlined[d.Subject] {
+			continue
+		}
+		for _, l := range lits {
+			if l.file == d.File && l.line == d.Line && l.col == d.Col {
+				found = append(found, l)
+			}
+		}
+	}
+	return found
+}
+
+// inside reports whether d is within the body of one of lits.
+func inside(lits []*calledLit, d Diag) bool {
+	for _, l := range lits {
+		if l.file != d.File {
+			continue
+		}
+		after := d.Line > l.line || d.Line == l.line && d.Col >= l.col
+		before := d.Line < l.endLine || d.Line == l.endLine && d.Col < l.endCol
+		if after && before {
+			return true
+		}
+	}
+	return false
+}
+
+// declName returns the name the compiler uses for fn.
+func declName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	switch t := fn.Recv.List[0].Type.(type) {
+	case *ast.StarExpr:
+		if id, ok := t.X.(*ast.Ident); ok {
+			return "(*" + id.Name + ")." + fn.Name.Name
+		}
+	case *ast.Ident:
+		return t.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// callees returns the helpers d calls, directly or indirectly.
+func callees(d *funcDecl, info *types.Info, byObj map[types.Object]*funcDecl) []*funcDecl {
+	var found []*funcDecl
+	seen := map[*funcDecl]bool{d: true}
+	queue := []*funcDecl{d}
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+		if fn.decl.Body == nil {
+			continue
+		}
+		ast.Inspect(fn.decl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			var id *ast.Ident
+			switch f := call.Fun.(type) {
+			case *ast.Ident:
+				id = f
+			case *ast.SelectorExpr:
+				id = f.Sel
+			default:
+				return true
+			}
+			callee := byObj[info.Uses[id]]
+			if callee != nil && !seen[callee] && !bench.IsBenchmark(callee.decl) {
+				seen[callee] = true
+				found = append(found, callee)
+				queue = append(queue, callee)
+			}
+			return true
+		})
+	}
+	return found
+}
@@ -0,0 +1,123 @@
+package escape
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleOutput = `# example.com/pkg
+./x_test.go:5:6: can inline newThing with cost 4 as: func() *thing { return &thing{} }
+./x_test.go:9:6: cannot inline BenchmarkThing: function too complex: cost 95 exceeds budget 80
+./x_test.go:11:15: inlining call to newThing
+./x_test.go:11:15: inlining call to newThing
+./x_test.go:6:9: &thing{} escapes to heap in newThing:
+./x_test.go:6:9:   flow: ~r0 = &{storage for &thing{}}:
+./x_test.go:6:9: &thing{} escapes to heap
+./x_test.go:10:2: moved to heap: v
+./x_test.go:9:21: leaking param: b
+./x_test.go:11:15: &thing{} does not escape
+`
+
+const sampleSource = `package pkg
+
+type thing struct{}
+
+func newThing() *thing {
+	return &thing{}
+}
+
+func BenchmarkThing(b *testing.B) {
+	var v int
+	for i := 0; i < b.N; i++ {
+		sink = newThing()
+		sink2 = &v
+	}
+}
+`
+
+func TestParse(t *testing.T) {
+	diags, err := Parse(strings.NewReader(sampleOutput))
+	assert.NoError(t, err)
+
+	var kinds []Kind
+	for _, d := range diags {
+		kinds = append(kinds, d.Kind)
+	}
+	assert.Equal(t, []Kind{CanInline, CannotInline, Inlined, Escapes, MovedToHeap, Leak, NoEscape}, kinds)
+	assert.Equal(t, Diag{File: "x_test.go", Line: 9, Col: 6, Kind: CannotInline, Subject: "BenchmarkThing",
+		Reason: "function too complex: cost 95 exceeds budget 80"}, diags[1])
+	assert.Equal(t, "&thing{}", diags[3].Subject)
+	assert.Equal(t, "v", diags[4].Subject)
+}
+
+func TestSummarize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "escape")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "x_test.go"), []byte(sampleSource), 0644))
+
+	diags, err := Parse(strings.NewReader(sampleOutput))
+	assert.NoError(t, err)
+	summaries, err := Summarize(dir, diags)
+	assert.NoError(t, err)
+	if assert.Len(t, summaries, 1) {
+		s := summaries[0]
+		assert.Equal(t, "BenchmarkThing", s.Benchmark)
+		assert.Equal(t, []string{"newThing"}, s.Helpers)
+		assert.Equal(t, []string{"newThing"}, s.Inlined)
+		if assert.Len(t, s.Heap, 2) {
+			assert.Equal(t, "newThing", s.Heap[0].Func)
+			assert.Equal(t, "BenchmarkThing", s.Heap[1].Func)
+		}
+	}
+}
+
+const literalOutput = `# example.com/pkg
+./x_test.go:5:6: cannot inline BenchmarkFoo: function too complex: cost 95 exceeds budget 80
+./x_test.go:7:10: can inline BenchmarkFoo.func1 with cost 2 as: func() *Foo { return new(Foo) }
+./x_test.go:8:11: new(Foo) escapes to heap
+./x_test.go:9:4: inlining call to BenchmarkFoo.func1
+./x_test.go:9:4: new(Foo) escapes to heap
+`
+
+const literalSource = `package pkg
+
+type Foo struct{}
+
+func BenchmarkFoo(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		sink = func() *Foo {
+			return new(Foo)
+		}()
+	}
+}
+`
+
+func TestSummarizeInlinedLiteral(t *testing.T) {
+	dir, err := ioutil.TempDir("", "escape")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "x_test.go"), []byte(literalSource), 0644))
+
+	diags, err := Parse(strings.NewReader(literalOutput))
+	assert.NoError(t, err)
+	summaries, err := Summarize(dir, diags)
+	assert.NoError(t, err)
+	if assert.Len(t, summaries, 1) && assert.Len(t, summaries[0].Heap, 1) {
+		assert.Equal(t, 9, summaries[0].Heap[0].Line)
+	}
+}
+
+func TestKindText(t *testing.T) {
+	text, err := MovedToHeap.MarshalText()
+	assert.NoError(t, err)
+	var k Kind
+	assert.NoError(t, k.UnmarshalText(text))
+	assert.Equal(t, MovedToHeap, k)
+	assert.Error(t, k.UnmarshalText([]byte("bogus")))
+}
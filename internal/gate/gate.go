@@ -0,0 +1,124 @@
+// Package gate detects when the relative performance of declared benchmark
+// variants moves away from a baseline.
+package gate
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+)
+
+// Status is the outcome of checking a single pair.
+type Status string
+
+const (
+	OK Status = "ok"
+	// Moved means the ratio moved by more than the threshold.
+	Moved Status = "moved"
+	// New means the pair has no results in the baseline, so it isn't
+	// tracked yet.
+	New Status = "new"
+	// Missing means the pair is tracked by the baseline but has no results
+	// in the current run.
+	Missing Status = "missing"
+)
+
+// Change describes how the ratio of a pair moved between the baseline and
+// the current run. Ratios are the median ns/op of B divided by that of A, so
+// a ratio above one means A is faster.
+type Change struct {
+	compare.Pair
+	Baseline float64
+	Current  float64
+	// Delta is the relative change of the ratio in percent.
+	Delta float64
+	// Flipped reports whether the faster of the two variants changed.
+	Flipped bool
+	Status  Status
+}
+
+// Failed reports whether the change should fail the gate.
+func (c Change) Failed() bool {
+	return c.Status == Moved || c.Status == Missing
+}
+
+// Check compares the ratio of every pair in current against baseline. A pair
+// fails if its ratio moved by more than threshold percent in either
+// direction. Orderings which flip while staying within the threshold are
+// reported but don't fail on their own, since both variants are then within
+// noise of each other.
+func Check(baseline, current *bench.Set, pairs []compare.Pair, threshold float64) []Change {
+	changes := make([]Change, len(pairs))
+	for i, p := range pairs {
+		c := Change{
+			Pair:     p,
+			Baseline: ratio(baseline, p),
+			Current:  ratio(current, p),
+			Delta:    math.NaN(),
+			Status:   OK,
+		}
+		switch {
+		case math.IsNaN(c.Baseline):
+			c.Status = New
+		case math.IsNaN(c.Current):
+			c.Status = Missing
+		default:
+			c.Delta = 100 * (c.Current - c.Baseline) / c.Baseline
+			c.Flipped = (c.Baseline > 1) != (c.Current > 1)
+			if math.Abs(c.Delta) > threshold {
+				c.Status = Moved
+			}
+		}
+		changes[i] = c
+	}
+	return changes
+}
+
+// ratio returns the median ns/op of p.B divided by that of p.A in set, or NaN
+// if either is missing.
+func ratio(set *bench.Set, p compare.Pair) float64 {
+	a, b := compare.Samples(set, p.A), compare.Samples(set, p.B)
+	if len(a) == 0 || len(b) == 0 {
+		return math.NaN()
+	}
+	return b.Median() / a.Median()
+}
+
+// WriteDiff writes the changes as a table, listing failures first. If all is
+// false, only the pairs which failed or flipped are written.
+func WriteDiff(w io.Writer, changes []Change, all bool) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "status\tA\tB\tbaseline\tcurrent\tchange\n")
+	for _, failed := range []bool{true, false} {
+		for _, c := range changes {
+			if c.Failed() != failed || (!all && !failed && !c.Flipped) {
+				continue
+			}
+			status := string(c.Status)
+			if c.Flipped {
+				status += ", flipped"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				status, c.A, c.B, formatRatio(c.Baseline), formatRatio(c.Current), formatDelta(c.Delta))
+		}
+	}
+	return tw.Flush()
+}
+
+func formatRatio(r float64) string {
+	if math.IsNaN(r) {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", r)
+}
+
+func formatDelta(d float64) string {
+	if math.IsNaN(d) {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", d)
+}
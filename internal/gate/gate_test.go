@@ -0,0 +1,55 @@
+package gate
+
+import (
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+	"github.com/stretchr/testify/assert"
+)
+
+func set(results map[string]float64) *bench.Set {
+	s := &bench.Set{}
+	for name, ns := range results {
+		s.Results = append(s.Results, &bench.Result{Name: name, NsPerOp: ns})
+	}
+	return s
+}
+
+func TestCheck(t *testing.T) {
+	pairs := []compare.Pair{
+		{A: "BenchmarkMutexUnlock", B: "BenchmarkMutexDeferUnlock"},
+		{A: "BenchmarkPassByReferenceOneWord", B: "BenchmarkPassByValueOneWord"},
+		{A: "BenchmarkSyncBufferPool", B: "BenchmarkChannelBufferPool"},
+		{A: "BenchmarkWriteBytes", B: "BenchmarkWriteString"},
+	}
+	baseline := set(map[string]float64{
+		"BenchmarkMutexUnlock":            25,
+		"BenchmarkMutexDeferUnlock":       100,
+		"BenchmarkPassByReferenceOneWord": 2.0,
+		"BenchmarkPassByValueOneWord":     2.1,
+		"BenchmarkSyncBufferPool":         30,
+		"BenchmarkChannelBufferPool":      200,
+	})
+	current := set(map[string]float64{
+		"BenchmarkMutexUnlock":            25,
+		"BenchmarkMutexDeferUnlock":       30,
+		"BenchmarkPassByReferenceOneWord": 2.1,
+		"BenchmarkPassByValueOneWord":     2.0,
+		"BenchmarkWriteBytes":             20,
+		"BenchmarkWriteString":            60,
+	})
+
+	changes := Check(baseline, current, pairs, 20)
+	assert.Equal(t, Moved, changes[0].Status)
+	assert.InDelta(t, -70, changes[0].Delta, 1e-9)
+
+	assert.Equal(t, OK, changes[1].Status)
+	assert.True(t, changes[1].Flipped)
+
+	assert.Equal(t, Missing, changes[2].Status)
+	assert.True(t, changes[2].Failed())
+
+	assert.Equal(t, New, changes[3].Status)
+	assert.False(t, changes[3].Failed())
+}
@@ -0,0 +1,5 @@
+// Package harness contains the helpers shared by the benchmark packages: the
+// sinks which keep benchmark results alive, parameter sweeps over named axes,
+// and recorders for measuring more than go test reports on its own, such as
+// GC activity, queue latency and CPU performance counters.
+package harness
@@ -0,0 +1,126 @@
+package harness
+
+import (
+	"math"
+	"runtime/metrics"
+	"testing"
+)
+
+// The runtime/metrics read by GCRecorder, indexed by the constants below.
+// The GC pause histogram was renamed in Go 1.22, so both names are read and
+// whichever is supported is used.
+var gcMetrics = []string{
+	gcCycles:    "/gc/cycles/total:gc-cycles",
+	heapGoal:    "/gc/heap/goal:bytes",
+	scavengeCPU: "/cpu/classes/scavenge/total:cpu-seconds",
+	gcPauses:    "/sched/pauses/total/gc:seconds",
+	oldGCPauses: "/gc/pauses:seconds",
+}
+
+const (
+	gcCycles = iota
+	heapGoal
+	scavengeCPU
+	gcPauses
+	oldGCPauses
+)
+
+// GCRecorder measures the work the garbage collector does during the timed
+// region of a benchmark. Create one with RecordGC.
+type GCRecorder struct {
+	b      *testing.B
+	before []metrics.Sample
+}
+
+// RecordGC starts recording garbage collector activity for b. It should be
+// called right before the timed region, i.e. after any setup and call to
+// b.ResetTimer, and Stop called right after it, typically:
+//
+//	b.ResetTimer()
+//	defer harness.RecordGC(b).Stop()
+//
+// The metrics are only read before and after the timed region, with the
+// timer stopped, so recording doesn't add to the time of the benchmark.
+func RecordGC(b *testing.B) *GCRecorder {
+	b.StopTimer()
+	r := &GCRecorder{b: b, before: readGCMetrics()}
+	b.StartTimer()
+	return r
+}
+
+// Stop stops the timer and recording, and reports the following metrics for
+// the benchmark:
+//
+//	gc-cycles/op       garbage collections completed per operation
+//	gc-pause-ns/op     stop-the-world pause time caused by the GC per operation
+//	scavenge-ns/op     CPU time spent returning memory to the OS per operation
+//	heap-goal-bytes    heap size at which the GC will next collect, as set
+//	                   by the last collection of the timed region
+//
+// Metrics which the running Go version doesn't support are omitted. The
+// timer is left stopped, so Stop should end the benchmark.
+func (r *GCRecorder) Stop() {
+	r.b.StopTimer()
+	after := readGCMetrics()
+
+	n := float64(r.b.N)
+	if n == 0 {
+		return
+	}
+	if after[gcCycles].Value.Kind() == metrics.KindUint64 {
+		cycles := after[gcCycles].Value.Uint64() - r.before[gcCycles].Value.Uint64()
+		r.b.ReportMetric(float64(cycles)/n, "gc-cycles/op")
+	}
+	if pauses, ok := pauseDelta(r.before[gcPauses], after[gcPauses]); ok {
+		r.b.ReportMetric(pauses*1e9/n, "gc-pause-ns/op")
+	} else if pauses, ok := pauseDelta(r.before[oldGCPauses], after[oldGCPauses]); ok {
+		r.b.ReportMetric(pauses*1e9/n, "gc-pause-ns/op")
+	}
+	if after[scavengeCPU].Value.Kind() == metrics.KindFloat64 {
+		scavenge := after[scavengeCPU].Value.Float64() - r.before[scavengeCPU].Value.Float64()
+		r.b.ReportMetric(scavenge*1e9/n, "scavenge-ns/op")
+	}
+	if after[heapGoal].Value.Kind() == metrics.KindUint64 {
+		r.b.ReportMetric(float64(after[heapGoal].Value.Uint64()), "heap-goal-bytes")
+	}
+}
+
+// readGCMetrics reads the metrics in gcMetrics.
+func readGCMetrics() []metrics.Sample {
+	samples := make([]metrics.Sample, len(gcMetrics))
+	for i, name := range gcMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	return samples
+}
+
+// pauseDelta estimates the total pause time in seconds recorded by a pause
+// histogram between two samples, using the midpoint of each bucket.
+func pauseDelta(before, after metrics.Sample) (float64, bool) {
+	if before.Value.Kind() != metrics.KindFloat64Histogram || after.Value.Kind() != metrics.KindFloat64Histogram {
+		return 0, false
+	}
+	h0, h1 := before.Value.Float64Histogram(), after.Value.Float64Histogram()
+	var total float64
+	for i, c := range h1.Counts {
+		if i < len(h0.Counts) {
+			c -= h0.Counts[i]
+		}
+		if c == 0 {
+			continue
+		}
+		lo, hi := h1.Buckets[i], h1.Buckets[i+1]
+		var mid float64
+		switch {
+		case math.IsInf(lo, -1):
+			mid = hi
+		case math.IsInf(hi, 1):
+			mid = lo
+		default:
+			mid = (lo + hi) / 2
+		}
+		total += float64(c) * mid
+	}
+	return total, true
+}
@@ -0,0 +1,22 @@
+package harness
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordGC(t *testing.T) {
+	res := testing.Benchmark(func(b *testing.B) {
+		defer RecordGC(b).Stop()
+		runtime.GC()
+		for i := 0; i < b.N; i++ {
+			Sink.Any = make([]byte, 64)
+		}
+	})
+	assert.True(t, res.Extra["gc-cycles/op"] > 0, "gc-cycles/op")
+	assert.True(t, res.Extra["heap-goal-bytes"] > 0, "heap-goal-bytes")
+	assert.Contains(t, res.Extra, "gc-pause-ns/op")
+	assert.Contains(t, res.Extra, "scavenge-ns/op")
+}
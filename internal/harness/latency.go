@@ -0,0 +1,124 @@
+package harness
+
+import (
+	"flag"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/hdr"
+)
+
+var latency = flag.Bool("latency", false, "record per-item latency in the queue benchmarks")
+
+// The range and precision of the latency histograms. Latencies above the
+// highest trackable value are recorded as it.
+const (
+	latencyHighest = int64(10 * time.Second)
+	latencyDigits  = 2
+)
+
+// LatencyRecorder measures the time items spend in a queue, from being
+// enqueued by a producer to being dequeued by a consumer, and reports the
+// distribution as the p50-ns, p99-ns, p99.9-ns and max-ns metrics.
+//
+// Timestamping every item adds to the cost of an operation, so latency is
+// only recorded when the benchmarks are run with -latency, e.g.
+//
+//	go test -bench Channel -args -latency
+//
+// Otherwise NewLatencyRecorder returns nil and all its methods are cheap
+// no-ops which leave the items sent through the queue unchanged.
+type LatencyRecorder struct {
+	start time.Time
+
+	mu     sync.Mutex
+	merged *hdr.Histogram
+}
+
+// NewLatencyRecorder returns a LatencyRecorder, or nil if the benchmarks
+// were not run with -latency.
+func NewLatencyRecorder() *LatencyRecorder {
+	if !*latency {
+		return nil
+	}
+	return &LatencyRecorder{
+		start:  time.Now(),
+		merged: hdr.New(latencyHighest, latencyDigits),
+	}
+}
+
+// now returns the monotonic time elapsed since r was created.
+func (r *LatencyRecorder) now() int {
+	return int(time.Since(r.start))
+}
+
+// Item returns the item a producer should enqueue: a timestamp if latency is
+// being recorded and v otherwise.
+func (r *LatencyRecorder) Item(v int) int {
+	if r == nil {
+		return v
+	}
+	return r.now()
+}
+
+// Value is Item for queues of interface{} values.
+func (r *LatencyRecorder) Value(v interface{}) interface{} {
+	if r == nil {
+		return v
+	}
+	return r.now()
+}
+
+// Local returns a histogram for a single consumer to record into. Consumers
+// record into their own histogram so they don't contend with each other, and
+// then hand it back with Merge.
+func (r *LatencyRecorder) Local() *hdr.Histogram {
+	if r == nil {
+		return nil
+	}
+	return hdr.New(latencyHighest, latencyDigits)
+}
+
+// Record records the latency of an item, as returned by Item, dequeued by a
+// consumer into its local histogram.
+func (r *LatencyRecorder) Record(h *hdr.Histogram, item int) {
+	if r == nil {
+		return
+	}
+	h.Record(int64(r.now() - item))
+}
+
+// RecordValue is Record for queues of interface{} values.
+func (r *LatencyRecorder) RecordValue(h *hdr.Histogram, v interface{}) {
+	if r == nil {
+		return
+	}
+	if item, ok := v.(int); ok {
+		r.Record(h, item)
+	}
+}
+
+// Merge adds a consumer's local histogram to the distribution.
+func (r *LatencyRecorder) Merge(h *hdr.Histogram) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.merged.Merge(h)
+	r.mu.Unlock()
+}
+
+// Report reports the latency distribution as metrics of b. It should be
+// called after all consumers have merged their histograms.
+func (r *LatencyRecorder) Report(b *testing.B) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b.ReportMetric(float64(r.merged.Percentile(50)), "p50-ns")
+	b.ReportMetric(float64(r.merged.Percentile(99)), "p99-ns")
+	b.ReportMetric(float64(r.merged.Percentile(99.9)), "p99.9-ns")
+	b.ReportMetric(float64(r.merged.Max()), "max-ns")
+}
@@ -0,0 +1,35 @@
+package harness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyRecorderDisabled(t *testing.T) {
+	var r *LatencyRecorder
+	assert.Equal(t, 7, r.Item(7))
+	assert.Equal(t, "x", r.Value("x"))
+	assert.Nil(t, r.Local())
+	r.Record(nil, 7)
+	r.Merge(nil)
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	defer func(v bool) { *latency = v }(*latency)
+	*latency = true
+
+	r := NewLatencyRecorder()
+	res := testing.Benchmark(func(b *testing.B) {
+		h := r.Local()
+		for i := 0; i < b.N; i++ {
+			r.RecordValue(h, r.Value(i))
+		}
+		r.Merge(h)
+		r.Report(b)
+	})
+	for _, unit := range []string{"p50-ns", "p99-ns", "p99.9-ns", "max-ns"} {
+		assert.Contains(t, res.Extra, unit)
+	}
+	assert.True(t, res.Extra["p50-ns"] <= res.Extra["max-ns"])
+}
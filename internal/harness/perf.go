@@ -0,0 +1,86 @@
+package harness
+
+import (
+	"sync"
+	"testing"
+)
+
+// perfUnavailable makes sure the reason perf counters can't be used is only
+// logged once per run rather than once per benchmark.
+var perfUnavailable sync.Once
+
+// PerfRecorder counts CPU events with the Linux perf_event_open interface
+// during the timed region of a benchmark. Create one with RecordPerf.
+//
+// Hardware counters are preferred. Where they aren't available, typically in
+// a virtual machine, software counters maintained by the kernel are used
+// instead. Only the events of the threads the process has when recording
+// starts, and of threads they start, are counted. The kernel only adds the
+// counts of a thread started while recording to its parent's when the thread
+// exits, so the work of new threads which are still running at Stop, as the
+// runtime usually keeps them, is under-counted.
+type PerfRecorder struct {
+	b        *testing.B
+	counters *perfCounters
+}
+
+// perfValue is the total count of an event while recording.
+type perfValue struct {
+	unit  string
+	value float64
+}
+
+// RecordPerf starts counting CPU events for b. Like RecordGC it should be
+// called right before the timed region and stopped right after it:
+//
+//	b.ResetTimer()
+//	defer harness.RecordPerf(b).Stop()
+//
+// The counters are opened, one per event and thread, with the timer stopped.
+// If no counters can be opened, e.g. on an OS other than Linux or because
+// kernel.perf_event_paranoid forbids it, the reason is logged and Stop
+// reports nothing.
+func RecordPerf(b *testing.B) *PerfRecorder {
+	r := &PerfRecorder{b: b}
+	b.StopTimer()
+	defer b.StartTimer()
+	c, err := openPerfCounters()
+	if err != nil {
+		perfUnavailable.Do(func() { b.Logf("perf counters unavailable: %v", err) })
+		return r
+	}
+	r.counters = c
+	return r
+}
+
+// Stop stops counting and reports each event per operation. With hardware
+// counters the metrics are:
+//
+//	instructions/op        instructions retired
+//	cycles/op              CPU cycles
+//	LLC-misses/op          last level cache read misses
+//	context-switches/op    context switches
+//
+// and with software counters:
+//
+//	task-clock-ns/op       CPU time
+//	context-switches/op    context switches
+//	cpu-migrations/op      moves of a thread to another CPU
+//	page-faults/op         page faults
+//
+// The timer is left stopped while the counters are read and closed, so Stop
+// should end the benchmark.
+func (r *PerfRecorder) Stop() {
+	r.b.StopTimer()
+	if r.counters == nil {
+		return
+	}
+	values := r.counters.stop()
+	n := float64(r.b.N)
+	if n == 0 {
+		return
+	}
+	for _, v := range values {
+		r.b.ReportMetric(v.value/n, v.unit)
+	}
+}
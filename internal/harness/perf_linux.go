@@ -0,0 +1,198 @@
+//go:build linux
+// +build linux
+
+package harness
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// Values from include/uapi/linux/perf_event.h.
+const (
+	perfTypeHardware = 0
+	perfTypeSoftware = 1
+	perfTypeHWCache  = 3
+
+	perfCountHWCPUCycles    = 0
+	perfCountHWInstructions = 1
+
+	perfCountHWCacheLL         = 2
+	perfCountHWCacheOpRead     = 0
+	perfCountHWCacheResultMiss = 1
+
+	perfCountSWTaskClock       = 1
+	perfCountSWPageFaults      = 2
+	perfCountSWContextSwitches = 3
+	perfCountSWCPUMigrations   = 4
+
+	perfFormatTotalTimeEnabled = 1 << 0
+	perfFormatTotalTimeRunning = 1 << 1
+
+	perfAttrDisabled      = 1 << 0
+	perfAttrInherit       = 1 << 1
+	perfAttrExcludeKernel = 1 << 5
+	perfAttrExcludeHV     = 1 << 6
+
+	perfFlagFDCloexec = 1 << 3
+
+	perfEventIocEnable  = 0x2400
+	perfEventIocDisable = 0x2401
+)
+
+// perfEventAttr is struct perf_event_attr up to PERF_ATTR_SIZE_VER5.
+type perfEventAttr struct {
+	Type             uint32
+	Size             uint32
+	Config           uint64
+	SamplePeriod     uint64
+	SampleType       uint64
+	ReadFormat       uint64
+	Flags            uint64
+	WakeupEvents     uint32
+	BpType           uint32
+	Config1          uint64
+	Config2          uint64
+	BranchSampleType uint64
+	SampleRegsUser   uint64
+	SampleStackUser  uint32
+	ClockID          int32
+	SampleRegsIntr   uint64
+	AuxWatermark     uint32
+	SampleMaxStack   uint16
+	_                uint16
+}
+
+type perfEvent struct {
+	unit   string
+	typ    uint32
+	config uint64
+}
+
+var (
+	hardwareEvents = []perfEvent{
+		{"instructions/op", perfTypeHardware, perfCountHWInstructions},
+		{"cycles/op", perfTypeHardware, perfCountHWCPUCycles},
+		{"LLC-misses/op", perfTypeHWCache, perfCountHWCacheLL | perfCountHWCacheOpRead<<8 | perfCountHWCacheResultMiss<<16},
+		{"context-switches/op", perfTypeSoftware, perfCountSWContextSwitches},
+	}
+	softwareEvents = []perfEvent{
+		{"task-clock-ns/op", perfTypeSoftware, perfCountSWTaskClock},
+		{"context-switches/op", perfTypeSoftware, perfCountSWContextSwitches},
+		{"cpu-migrations/op", perfTypeSoftware, perfCountSWCPUMigrations},
+		{"page-faults/op", perfTypeSoftware, perfCountSWPageFaults},
+	}
+)
+
+// perfCounters holds a file descriptor per event and thread, fds[i] being
+// the descriptors of events[i].
+type perfCounters struct {
+	events []perfEvent
+	fds    [][]int
+}
+
+// openPerfCounters opens and enables the hardware events, falling back to
+// the software events if any hardware event can't be opened. Events in the
+// kernel are only counted if kernel.perf_event_paranoid allows it.
+func openPerfCounters() (*perfCounters, error) {
+	tids, err := threads()
+	if err != nil {
+		return nil, err
+	}
+	for _, events := range [][]perfEvent{hardwareEvents, softwareEvents} {
+		for _, exclude := range []uint64{0, perfAttrExcludeKernel | perfAttrExcludeHV} {
+			var c *perfCounters
+			c, err = openEvents(events, tids, exclude)
+			if err == nil {
+				c.ioctl(perfEventIocEnable)
+				return c, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+// threads returns the ids of the threads of the process.
+func threads() ([]int, error) {
+	entries, err := ioutil.ReadDir("/proc/self/task")
+	if err != nil {
+		return nil, err
+	}
+	var tids []int
+	for _, e := range entries {
+		if tid, err := strconv.Atoi(e.Name()); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+	return tids, nil
+}
+
+func openEvents(events []perfEvent, tids []int, flags uint64) (*perfCounters, error) {
+	c := &perfCounters{events: events, fds: make([][]int, len(events))}
+	for i, ev := range events {
+		attr := perfEventAttr{
+			Type:       ev.typ,
+			Config:     ev.config,
+			ReadFormat: perfFormatTotalTimeEnabled | perfFormatTotalTimeRunning,
+			Flags:      perfAttrDisabled | perfAttrInherit | flags,
+		}
+		attr.Size = uint32(unsafe.Sizeof(attr))
+		for _, tid := range tids {
+			fd, _, errno := syscall.Syscall6(syscall.SYS_PERF_EVENT_OPEN,
+				uintptr(unsafe.Pointer(&attr)), uintptr(tid), ^uintptr(0), ^uintptr(0), perfFlagFDCloexec, 0)
+			if errno == syscall.ESRCH {
+				// The thread exited since it was listed.
+				continue
+			}
+			if errno != 0 {
+				c.close()
+				return nil, fmt.Errorf("perf_event_open %s: %v", ev.unit, errno)
+			}
+			c.fds[i] = append(c.fds[i], int(fd))
+		}
+	}
+	return c, nil
+}
+
+func (c *perfCounters) ioctl(req uintptr) {
+	for _, fds := range c.fds {
+		for _, fd := range fds {
+			syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, 0)
+		}
+	}
+}
+
+func (c *perfCounters) close() {
+	for _, fds := range c.fds {
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+	}
+}
+
+// stop disables and closes the counters and returns the total of each
+// event. Counts are scaled up if the kernel had to multiplex the counters
+// because there were more events than hardware counters.
+func (c *perfCounters) stop() []perfValue {
+	c.ioctl(perfEventIocDisable)
+	defer c.close()
+
+	values := make([]perfValue, 0, len(c.events))
+	for i, ev := range c.events {
+		var total float64
+		for _, fd := range c.fds[i] {
+			// value, time enabled, time running
+			var buf [3]uint64
+			n, err := syscall.Read(fd, (*[24]byte)(unsafe.Pointer(&buf))[:])
+			if err != nil || n != len(buf)*8 || buf[2] == 0 {
+				continue
+			}
+			total += float64(buf[0]) * float64(buf[1]) / float64(buf[2])
+		}
+		values = append(values, perfValue{unit: ev.unit, value: total})
+	}
+	return values
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package harness
+
+import "errors"
+
+type perfCounters struct{}
+
+func openPerfCounters() (*perfCounters, error) {
+	return nil, errors.New("only supported on Linux")
+}
+
+func (c *perfCounters) stop() []perfValue { return nil }
@@ -0,0 +1,23 @@
+package harness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordPerf(t *testing.T) {
+	c, err := openPerfCounters()
+	if err != nil {
+		t.Skipf("perf counters unavailable: %v", err)
+	}
+	c.stop()
+
+	res := testing.Benchmark(func(b *testing.B) {
+		defer RecordPerf(b).Stop()
+		for i := 0; i < b.N; i++ {
+			Sink.Int = i
+		}
+	})
+	assert.Contains(t, res.Extra, "context-switches/op")
+}
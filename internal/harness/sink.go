@@ -0,0 +1,32 @@
+package harness
+
+// Sink holds variables which benchmarks assign their results to. The compiler
+// can't prove a value stored in a package level variable is never read, so it
+// can't delete the work which computed it:
+//
+//	for i := 0; i < b.N; i++ {
+//		harness.Sink.Uint64 = h.Sum64()
+//	}
+//
+// Storing a pointer in a sink makes what it points to escape to the heap, so
+// benchmarks of stack allocation should sink a value read through the pointer
+// instead. cmd/dceguard reports benchmarks which need a sink.
+//
+// Parallel benchmarks should sink once per goroutine after their loop, with
+// an atomic store so that the goroutines don't race.
+var Sink struct {
+	// The 64-bit fields come first so that they are aligned for atomic
+	// access on 32-bit platforms.
+	Int64   int64
+	Uint64  uint64
+	Bool    bool
+	Byte    byte
+	Int     int
+	Int32   int32
+	Uint32  uint32
+	Uint128 [2]uint64
+	Float64 float64
+	String  string
+	Bytes   []byte
+	Any     interface{}
+}
@@ -0,0 +1,220 @@
+package harness
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+)
+
+// Sweep runs a benchmark over the cartesian product of named parameter axes,
+// replacing hand-written wrappers such as BenchmarkMapString1 …
+// BenchmarkMapString10000 with one sub-benchmark per combination of values:
+//
+//	func BenchmarkMapString(b *testing.B) {
+//		axes := []harness.Axis{{Name: "keylen", Values: []int{1, 10, 100}}}
+//		harness.Sweep(b, axes, func(p harness.Params) func(b *testing.B) {
+//			keys := genKeys(p.Int("keylen"))
+//			return func(b *testing.B) { ... }
+//		})
+//	}
+//
+// Each axis becomes a level of sub-benchmark named axis=value, e.g.
+// BenchmarkMapString/keylen=100, with values which are a multiple of 1K or
+// 1M written as such. The values of any axis can be replaced when running
+// the benchmarks, either with -sweep:
+//
+//	go test -bench MapString -args -sweep keylen=1,32,1K -sweep len=4K
+//
+// or with a JSON file mapping axis names to their values:
+//
+//	go test -bench MapString -args -sweep.file sweep.json
+//
+//	{"keylen": [1, 32, "1K"]}
+//
+// Values given with -sweep take precedence over the file.
+//
+// setup is called in each sub-benchmark to prepare the case and returns the
+// function to time. The timer is reset after setup, so the time and
+// allocations it takes are excluded from the results.
+func Sweep(b *testing.B, axes []Axis, setup func(p Params) func(b *testing.B)) {
+	axes, err := overrideAxes(axes)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sweep(b, axes, Params{}, setup)
+}
+
+// Axis is a named parameter and the values a benchmark is run with.
+type Axis struct {
+	Name   string
+	Values []int
+}
+
+// Params holds one value of each axis of a sweep.
+type Params struct {
+	names  []string
+	values []int
+}
+
+// Int returns the value of the named axis. It panics if there is no such
+// axis, which is always a mistake in the benchmark.
+func (p Params) Int(name string) int {
+	for i, n := range p.names {
+		if n == name {
+			return p.values[i]
+		}
+	}
+	panic("harness: no axis named " + name)
+}
+
+// String returns the sub-benchmark name of p, e.g. bits=1000/len=16K.
+func (p Params) String() string {
+	parts := make([]string, len(p.names))
+	for i := range p.names {
+		parts[i] = p.names[i] + "=" + bench.FormatSize(p.values[i])
+	}
+	return strings.Join(parts, "/")
+}
+
+func (p Params) with(name string, v int) Params {
+	n := len(p.names)
+	return Params{
+		names:  append(p.names[:n:n], name),
+		values: append(p.values[:n:n], v),
+	}
+}
+
+// Cases returns the cartesian product of axes, varying the last axis
+// fastest.
+func Cases(axes []Axis) []Params {
+	cases := []Params{{}}
+	for _, axis := range axes {
+		next := make([]Params, 0, len(cases)*len(axis.Values))
+		for _, c := range cases {
+			for _, v := range axis.Values {
+				next = append(next, c.with(axis.Name, v))
+			}
+		}
+		cases = next
+	}
+	return cases
+}
+
+func sweep(b *testing.B, axes []Axis, p Params, setup func(p Params) func(b *testing.B)) {
+	if len(axes) == 0 {
+		fn := setup(p)
+		b.ResetTimer()
+		fn(b)
+		return
+	}
+	axis := axes[0]
+	for _, v := range axis.Values {
+		p := p.with(axis.Name, v)
+		b.Run(axis.Name+"="+bench.FormatSize(v), func(b *testing.B) {
+			sweep(b, axes[1:], p, setup)
+		})
+	}
+}
+
+var (
+	sweepOverrides = make(axisFlag)
+	sweepFile      = flag.String("sweep.file", "", "JSON file of sweep axis values, e.g. {\"keylen\": [1, \"1K\"]}")
+
+	sweepFileOnce sync.Once
+	fileOverrides map[string][]int
+	fileErr       error
+)
+
+func init() {
+	flag.Var(sweepOverrides, "sweep", "replace the values of a sweep axis, e.g. keylen=1,32,1K (repeatable)")
+}
+
+// overrideAxes returns axes with the values of any axis named with -sweep or
+// in the -sweep.file replaced.
+func overrideAxes(axes []Axis) ([]Axis, error) {
+	sweepFileOnce.Do(func() {
+		if *sweepFile != "" {
+			fileOverrides, fileErr = loadSweepFile(*sweepFile)
+		}
+	})
+	if fileErr != nil {
+		return nil, fileErr
+	}
+	out := make([]Axis, len(axes))
+	for i, axis := range axes {
+		if vs, ok := fileOverrides[axis.Name]; ok {
+			axis.Values = vs
+		}
+		if vs, ok := sweepOverrides[axis.Name]; ok {
+			axis.Values = vs
+		}
+		out[i] = axis
+	}
+	return out, nil
+}
+
+// loadSweepFile reads a JSON object mapping axis names to lists of values, each
+// either a number or a size such as "16K".
+func loadSweepFile(path string) (map[string][]int, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string][]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("harness: %s: %v", path, err)
+	}
+	axes := make(map[string][]int, len(raw))
+	for name, values := range raw {
+		for _, msg := range values {
+			var s string
+			if err := json.Unmarshal(msg, &s); err != nil {
+				s = string(msg)
+			}
+			v, err := bench.ParseSize(s)
+			if err != nil {
+				return nil, fmt.Errorf("harness: %s: axis %s: %v", path, name, err)
+			}
+			axes[name] = append(axes[name], v)
+		}
+	}
+	return axes, nil
+}
+
+// axisFlag accumulates name=v1,v2,... arguments.
+type axisFlag map[string][]int
+
+func (f axisFlag) String() string {
+	var parts []string
+	for name, values := range f {
+		vs := make([]string, len(values))
+		for i, v := range values {
+			vs[i] = bench.FormatSize(v)
+		}
+		parts = append(parts, name+"="+strings.Join(vs, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f axisFlag) Set(s string) error {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return fmt.Errorf("want axis=value,..., got %q", s)
+	}
+	var values []int
+	for _, field := range strings.Split(s[i+1:], ",") {
+		v, err := bench.ParseSize(field)
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
+	}
+	f[s[:i]] = values
+	return nil
+}
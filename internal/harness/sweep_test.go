@@ -0,0 +1,65 @@
+package harness
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCases(t *testing.T) {
+	cases := Cases([]Axis{
+		{Name: "impl", Values: []int{0, 1}},
+		{Name: "len", Values: []int{1000, 16 << 10, 1 << 20}},
+	})
+	var names []string
+	for _, c := range cases {
+		names = append(names, c.String())
+	}
+	assert.Equal(t, []string{
+		"impl=0/len=1000", "impl=0/len=16K", "impl=0/len=1M",
+		"impl=1/len=1000", "impl=1/len=16K", "impl=1/len=1M",
+	}, names)
+	assert.Equal(t, 1, cases[4].Int("impl"))
+	assert.Equal(t, 16<<10, cases[4].Int("len"))
+	assert.Panics(t, func() { cases[0].Int("size") })
+
+	assert.Len(t, Cases(nil), 1)
+	assert.Empty(t, Cases([]Axis{{Name: "len"}}))
+}
+
+func TestAxisFlag(t *testing.T) {
+	f := make(axisFlag)
+	assert.NoError(t, f.Set("keylen=1,32,1K"))
+	assert.Equal(t, []int{1, 32, 1024}, f["keylen"])
+	assert.Error(t, f.Set("keylen"))
+	assert.Error(t, f.Set("keylen=x"))
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "harness")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sweep.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"keylen": [1, "1K"]}`), 0644))
+	axes, err := loadSweepFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]int{"keylen": {1, 1024}}, axes)
+}
+
+func TestSweep(t *testing.T) {
+	var setups int
+	seen := make(map[string]bool)
+	testing.Benchmark(func(b *testing.B) {
+		Sweep(b, []Axis{{Name: "len", Values: []int{1, 2}}}, func(p Params) func(b *testing.B) {
+			setups++
+			seen[p.String()] = true
+			return func(b *testing.B) {}
+		})
+	})
+	assert.True(t, setups >= 2)
+	assert.Equal(t, map[string]bool{"len=1": true, "len=2": true}, seen)
+}
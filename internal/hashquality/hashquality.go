@@ -0,0 +1,356 @@
+// Package hashquality measures how evenly hash functions distribute their
+// outputs with tests modelled on SMHasher: avalanche, differential, sparse
+// key, cyclic key and bucket chi-square. Each test returns a score and a
+// limit, so that weak hashes such as Adler-32 can be judged by more than
+// their throughput.
+package hashquality
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Func hashes key. Hashes of up to 64 bits return their output in lo.
+type Func func(key []byte) (lo, hi uint64)
+
+// Hash is a hash function under test.
+type Hash struct {
+	Name string
+	// Bits is the width of the output, from 32 up to 128.
+	Bits int
+	Sum  Func
+}
+
+// sum hashes key and clears any bits above the width of h.
+func (h Hash) sum(key []byte) (lo, hi uint64) {
+	lo, hi = h.Sum(key)
+	switch {
+	case h.Bits < 64:
+		return lo & (1<<uint(h.Bits) - 1), 0
+	case h.Bits == 64:
+		return lo, 0
+	case h.Bits < 128:
+		return lo, hi & (1<<uint(h.Bits-64) - 1)
+	}
+	return lo, hi
+}
+
+// Config sets the number and shape of the keys each test hashes.
+type Config struct {
+	Seed int64
+	// AvalancheKeys random keys of AvalancheLen bytes have each of their
+	// bits flipped in turn.
+	AvalancheKeys int
+	AvalancheLen  int
+	// DifferentialKeys random 8 byte keys are flipped by every differential
+	// of up to DifferentialBits bits.
+	DifferentialKeys int
+	DifferentialBits int
+	// Sparse keys are every key of SparseLen bytes with at most SparseBits
+	// bits set.
+	SparseLen  int
+	SparseBits int
+	// CyclicKeys keys each repeat a random block of CyclicLen bytes
+	// CyclicRepeats times.
+	CyclicKeys    int
+	CyclicLen     int
+	CyclicRepeats int
+	// BucketKeys sequential 4 byte keys are hashed into 2^BucketBits
+	// buckets.
+	BucketKeys int
+	BucketBits int
+}
+
+// DefaultConfig takes a few seconds per hash.
+var DefaultConfig = Config{
+	Seed:             1,
+	AvalancheKeys:    10000,
+	AvalancheLen:     16,
+	DifferentialKeys: 1000,
+	DifferentialBits: 2,
+	SparseLen:        32,
+	SparseBits:       2,
+	CyclicKeys:       100000,
+	CyclicLen:        8,
+	CyclicRepeats:    8,
+	BucketKeys:       1 << 16,
+	BucketBits:       10,
+}
+
+// Tests are the names of the tests in the order Run returns them.
+var Tests = []string{"avalanche", "differential", "sparse", "cyclic", "chi2"}
+
+// Result is the outcome of a single test. Lower scores are better.
+type Result struct {
+	Test string
+	// Score is the worst bias of an output bit for avalanche, the number of
+	// collisions for differential, sparse and cyclic, and the z-score of
+	// the worst bucket chi-square statistic for chi2.
+	Score float64
+	// Limit is the highest score which passes.
+	Limit float64
+}
+
+// Pass reports whether the score is within the limit.
+func (r Result) Pass() bool {
+	return r.Score <= r.Limit
+}
+
+// Run runs every test against h.
+func Run(h Hash, cfg Config) []Result {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	return []Result{
+		Avalanche(h, cfg, rng),
+		Differential(h, cfg, rng),
+		Sparse(h, cfg),
+		Cyclic(h, cfg, rng),
+		Buckets(h, cfg),
+	}
+}
+
+// Avalanche checks that flipping any bit of the input flips each bit of
+// the output with probability one half. The score is the worst bias,
+// |2p-1|, over every pair of input and output bits, and the limit is
+// SMHasher's 1% plus five standard errors of the sampling noise.
+func Avalanche(h Hash, cfg Config, rng *rand.Rand) Result {
+	inBits := cfg.AvalancheLen * 8
+	counts := make([]int, inBits*h.Bits)
+	key := make([]byte, cfg.AvalancheLen)
+	for k := 0; k < cfg.AvalancheKeys; k++ {
+		rng.Read(key)
+		lo0, hi0 := h.sum(key)
+		for i := 0; i < inBits; i++ {
+			key[i/8] ^= 1 << uint(i%8)
+			lo, hi := h.sum(key)
+			key[i/8] ^= 1 << uint(i%8)
+			row := counts[i*h.Bits : (i+1)*h.Bits]
+			for d := lo ^ lo0; d != 0; d &= d - 1 {
+				row[bits.TrailingZeros64(d)]++
+			}
+			for d := hi ^ hi0; d != 0; d &= d - 1 {
+				row[64+bits.TrailingZeros64(d)]++
+			}
+		}
+	}
+
+	worst := 0.0
+	n := float64(cfg.AvalancheKeys)
+	for _, c := range counts {
+		if bias := math.Abs(2*float64(c)/n - 1); bias > worst {
+			worst = bias
+		}
+	}
+	return Result{Test: "avalanche", Score: worst, Limit: 0.01 + 5/math.Sqrt(n)}
+}
+
+// Differential counts the keys whose hash is unchanged when flipped by a
+// differential of a few bits, which a good hash makes as unlikely as any
+// other collision.
+func Differential(h Hash, cfg Config, rng *rand.Rand) Result {
+	const keyLen = 8
+	var diffs [][]int
+	subsets(keyLen*8, cfg.DifferentialBits, func(idx []int) {
+		if len(idx) > 0 {
+			diffs = append(diffs, append([]int(nil), idx...))
+		}
+	})
+
+	collisions := 0
+	key := make([]byte, keyLen)
+	for k := 0; k < cfg.DifferentialKeys; k++ {
+		rng.Read(key)
+		lo0, hi0 := h.sum(key)
+		for _, d := range diffs {
+			flip(key, d)
+			lo, hi := h.sum(key)
+			flip(key, d)
+			if lo == lo0 && hi == hi0 {
+				collisions++
+			}
+		}
+	}
+	expected := math.Ldexp(float64(cfg.DifferentialKeys*len(diffs)), -h.Bits)
+	return Result{Test: "differential", Score: float64(collisions), Limit: collisionLimit(expected)}
+}
+
+// Sparse counts the collisions between all the keys with only a few bits
+// set, which trip up hashes that mix zero bytes poorly.
+func Sparse(h Hash, cfg Config) Result {
+	var outputs [][2]uint64
+	key := make([]byte, cfg.SparseLen)
+	subsets(cfg.SparseLen*8, cfg.SparseBits, func(idx []int) {
+		flip(key, idx)
+		lo, hi := h.sum(key)
+		flip(key, idx)
+		outputs = append(outputs, [2]uint64{lo, hi})
+	})
+	return collisionResult("sparse", h, outputs)
+}
+
+// Cyclic counts the collisions between keys made of a short block repeated
+// several times, which trip up hashes that process the input in blocks
+// without mixing in their position.
+func Cyclic(h Hash, cfg Config, rng *rand.Rand) Result {
+	outputs := make([][2]uint64, cfg.CyclicKeys)
+	block := make([]byte, cfg.CyclicLen)
+	key := make([]byte, 0, cfg.CyclicLen*cfg.CyclicRepeats)
+	for i := range outputs {
+		rng.Read(block)
+		key = key[:0]
+		for r := 0; r < cfg.CyclicRepeats; r++ {
+			key = append(key, block...)
+		}
+		lo, hi := h.sum(key)
+		outputs[i] = [2]uint64{lo, hi}
+	}
+	return collisionResult("cyclic", h, outputs)
+}
+
+// Buckets hashes sequential integers into buckets chosen by both the lowest
+// and the highest bits of the output, as hash tables do, and checks the
+// counts with a chi-square test. The score is the larger z-score of the two
+// statistics and fails above 4, which a uniform hash exceeds by chance
+// less than once in ten thousand runs.
+func Buckets(h Hash, cfg Config) Result {
+	n := 1 << uint(cfg.BucketBits)
+	low, high := make([]int, n), make([]int, n)
+	key := make([]byte, 4)
+	for i := 0; i < cfg.BucketKeys; i++ {
+		key[0], key[1], key[2], key[3] = byte(i), byte(i>>8), byte(i>>16), byte(i>>24)
+		lo, hi := h.sum(key)
+		low[lo&uint64(n-1)]++
+		high[topBits(lo, hi, h.Bits, cfg.BucketBits)]++
+	}
+	z := math.Max(chiSquareZ(low, cfg.BucketKeys), chiSquareZ(high, cfg.BucketKeys))
+	return Result{Test: "chi2", Score: z, Limit: 4}
+}
+
+// chiSquareZ returns the chi-square statistic of counts against a uniform
+// distribution of total keys, normalized to a z-score.
+func chiSquareZ(counts []int, total int) float64 {
+	expected := float64(total) / float64(len(counts))
+	chi2 := 0.0
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	df := float64(len(counts) - 1)
+	return (chi2 - df) / math.Sqrt(2*df)
+}
+
+// topBits returns the highest n bits of a width bit output.
+func topBits(lo, hi uint64, width, n int) uint64 {
+	mask := uint64(1)<<uint(n) - 1
+	shift := uint(width - n)
+	switch {
+	case shift >= 64:
+		return hi >> (shift - 64) & mask
+	case width <= 64:
+		return lo >> shift & mask
+	}
+	return (lo>>shift | hi<<(64-shift)) & mask
+}
+
+// collisionResult counts the collisions in outputs against the number
+// expected of a random function of the width of h.
+func collisionResult(test string, h Hash, outputs [][2]uint64) Result {
+	sort.Slice(outputs, func(i, j int) bool {
+		if outputs[i][1] != outputs[j][1] {
+			return outputs[i][1] < outputs[j][1]
+		}
+		return outputs[i][0] < outputs[j][0]
+	})
+	collisions := 0
+	for i := 1; i < len(outputs); i++ {
+		if outputs[i] == outputs[i-1] {
+			collisions++
+		}
+	}
+	n := float64(len(outputs))
+	expected := math.Ldexp(n*(n-1)/2, -h.Bits)
+	return Result{Test: test, Score: float64(collisions), Limit: collisionLimit(expected)}
+}
+
+// collisionLimit allows four standard deviations above the expected number
+// of collisions, which are Poisson distributed.
+func collisionLimit(expected float64) float64 {
+	return math.Ceil(expected + 4*math.Sqrt(expected))
+}
+
+// subsets calls fn with the indexes of every subset of n bits with at most
+// k bits, starting with the empty set. fn must not keep idx.
+func subsets(n, k int, fn func(idx []int)) {
+	idx := make([]int, 0, k)
+	var walk func(start int)
+	walk = func(start int) {
+		fn(idx)
+		if len(idx) == k {
+			return
+		}
+		for i := start; i < n; i++ {
+			idx = append(idx, i)
+			walk(i + 1)
+			idx = idx[:len(idx)-1]
+		}
+	}
+	walk(0)
+}
+
+// flip flips the bits of key at idx.
+func flip(key []byte, idx []int) {
+	for _, i := range idx {
+		key[i/8] ^= 1 << uint(i%8)
+	}
+}
+
+// Report is the quality and throughput of a single hash.
+type Report struct {
+	Name string
+	Bits int
+	// GBPerSec holds the throughput at each of the sizes the matrix is
+	// written with, or zero where it wasn't measured.
+	GBPerSec []float64
+	Results  []Result
+}
+
+// WriteMatrix writes a row per report with its throughput, labelled by
+// speeds, followed by the verdict and score of every test and the number of
+// tests passed.
+func WriteMatrix(w io.Writer, speeds []string, reports []*Report) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "hash\tbits\t")
+	for _, s := range speeds {
+		fmt.Fprintf(tw, "%s\t", s)
+	}
+	for _, t := range Tests {
+		fmt.Fprintf(tw, "%s\t", t)
+	}
+	fmt.Fprintf(tw, "passed\n")
+	for _, r := range reports {
+		fmt.Fprintf(tw, "%s\t%d\t", r.Name, r.Bits)
+		for i := range speeds {
+			if i < len(r.GBPerSec) && r.GBPerSec[i] > 0 {
+				fmt.Fprintf(tw, "%.2f\t", r.GBPerSec[i])
+			} else {
+				fmt.Fprintf(tw, "-\t")
+			}
+		}
+		passed := 0
+		for _, res := range r.Results {
+			verdict := "FAIL"
+			if res.Pass() {
+				verdict = "pass"
+				passed++
+			}
+			fmt.Fprintf(tw, "%s %s\t", verdict, strconv.FormatFloat(res.Score, 'g', 3, 64))
+		}
+		fmt.Fprintf(tw, "%d/%d\n", passed, len(r.Results))
+	}
+	return tw.Flush()
+}
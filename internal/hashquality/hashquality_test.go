@@ -0,0 +1,78 @@
+package hashquality
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/adler32"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = Config{
+	Seed:             1,
+	AvalancheKeys:    1000,
+	AvalancheLen:     8,
+	DifferentialKeys: 100,
+	DifferentialBits: 2,
+	SparseLen:        16,
+	SparseBits:       2,
+	CyclicKeys:       10000,
+	CyclicLen:        4,
+	CyclicRepeats:    4,
+	BucketKeys:       1 << 14,
+	BucketBits:       8,
+}
+
+var (
+	sha = Hash{Name: "sha256", Bits: 64, Sum: func(key []byte) (uint64, uint64) {
+		sum := sha256.Sum256(key)
+		return binary.LittleEndian.Uint64(sum[:]), 0
+	}}
+	adler = Hash{Name: "adler32", Bits: 32, Sum: func(key []byte) (uint64, uint64) {
+		return uint64(adler32.Checksum(key)), 0
+	}}
+)
+
+func TestRun(t *testing.T) {
+	results := Run(sha, testConfig)
+	assert.Len(t, results, len(Tests))
+	for i, r := range results {
+		assert.Equal(t, Tests[i], r.Test)
+		assert.True(t, r.Pass(), "%s: score %v above %v", r.Test, r.Score, r.Limit)
+	}
+
+	results = Run(adler, testConfig)
+	assert.False(t, results[0].Pass(), "avalanche")
+	assert.False(t, results[4].Pass(), "chi2")
+}
+
+func TestSubsets(t *testing.T) {
+	n := 0
+	subsets(16, 2, func(idx []int) { n++ })
+	assert.Equal(t, 1+16+16*15/2, n)
+}
+
+func TestTopBits(t *testing.T) {
+	assert.Equal(t, uint64(0x3ff), topBits(0xffc00000, 0, 32, 10))
+	assert.Equal(t, uint64(0x300), topBits(1<<63, 1, 65, 10))
+	assert.Equal(t, uint64(0x3ff), topBits(0, 0xffc0000000000000, 128, 10))
+}
+
+func TestWriteMatrix(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteMatrix(&buf, []string{"GB/s@16"}, []*Report{{
+		Name:     "adler32",
+		Bits:     32,
+		GBPerSec: []float64{1.5},
+		Results:  []Result{{Test: "avalanche", Score: 1, Limit: 0.05}, {Test: "differential", Score: 0, Limit: 1}},
+	}}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[1], "FAIL 1")
+	assert.Contains(t, lines[1], "pass 0")
+	assert.Contains(t, lines[1], "1.50")
+	assert.True(t, strings.HasSuffix(lines[1], "1/2"))
+}
@@ -0,0 +1,179 @@
+// Package hdr implements a High Dynamic Range histogram for recording
+// latencies.
+//
+// Like the original HdrHistogram, values are stored in buckets whose width
+// grows with the magnitude of the value so that every recorded value is
+// accurate to a fixed number of significant decimal digits, while memory use
+// stays small and recording is a couple of shifts and an increment.
+package hdr
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Histogram records integer values between 1 and a configurable maximum. It
+// is not safe for concurrent use; record into one histogram per goroutine and
+// merge them instead.
+type Histogram struct {
+	highest int64
+
+	subBucketHalfCountMagnitude uint
+	subBucketHalfCount          int64
+	subBucketMask               int64
+	subBucketCount              int64
+
+	counts []int64
+	total  int64
+	min    int64
+	max    int64
+	sum    float64
+}
+
+// New returns a histogram which tracks values up to highest with the given
+// number of significant decimal digits, which must be between 1 and 5.
+func New(highest int64, digits int) *Histogram {
+	if digits < 1 || digits > 5 {
+		panic("hdr: significant digits must be between 1 and 5")
+	}
+	if highest < 2 {
+		highest = 2
+	}
+
+	// The sub-buckets of each bucket must be able to distinguish values
+	// within 1 part in 10^digits.
+	largestSingleUnit := 2 * int64(math.Pow10(digits))
+	subBucketCountMagnitude := uint(math.Ceil(math.Log2(float64(largestSingleUnit))))
+	h := &Histogram{
+		highest:                     highest,
+		subBucketHalfCountMagnitude: subBucketCountMagnitude - 1,
+		subBucketCount:              1 << subBucketCountMagnitude,
+		min:                         math.MaxInt64,
+	}
+	h.subBucketHalfCount = h.subBucketCount / 2
+	h.subBucketMask = h.subBucketCount - 1
+
+	// Each bucket doubles the range covered by the previous one.
+	buckets := 1
+	for smallestUntrackable := h.subBucketCount; smallestUntrackable <= highest; buckets++ {
+		if smallestUntrackable > math.MaxInt64/2 {
+			buckets++
+			break
+		}
+		smallestUntrackable <<= 1
+	}
+	h.counts = make([]int64, (buckets+1)*int(h.subBucketHalfCount))
+	return h
+}
+
+// Record records v. Values below 1 are recorded as 1 and values above the
+// highest trackable value as the highest trackable value.
+func (h *Histogram) Record(v int64) {
+	if v < 1 {
+		v = 1
+	}
+	if v > h.highest {
+		v = h.highest
+	}
+	h.counts[h.index(v)]++
+	h.total++
+	h.sum += float64(v)
+	if v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+}
+
+// index returns the index of the counter v is recorded in.
+func (h *Histogram) index(v int64) int {
+	bucket := int64(bits.Len64(uint64(v|h.subBucketMask))) - int64(h.subBucketHalfCountMagnitude+1)
+	sub := v >> uint(bucket)
+	return int((bucket+1)<<h.subBucketHalfCountMagnitude + (sub - h.subBucketHalfCount))
+}
+
+// valueAt returns the highest value which is recorded in the counter at i.
+func (h *Histogram) valueAt(i int) int64 {
+	bucket := int64(i>>h.subBucketHalfCountMagnitude) - 1
+	sub := int64(i)&(h.subBucketHalfCount-1) + h.subBucketHalfCount
+	if bucket < 0 {
+		sub -= h.subBucketHalfCount
+		bucket = 0
+	}
+	lowest := sub << uint(bucket)
+	return lowest + (int64(1) << uint(bucket)) - 1
+}
+
+// Merge adds the values recorded in o to h. Both histograms must have been
+// created with the same parameters.
+func (h *Histogram) Merge(o *Histogram) {
+	if len(o.counts) != len(h.counts) {
+		panic("hdr: merging histograms with different parameters")
+	}
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	h.total += o.total
+	h.sum += o.sum
+	if o.total > 0 {
+		if o.min < h.min {
+			h.min = o.min
+		}
+		if o.max > h.max {
+			h.max = o.max
+		}
+	}
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() int64 { return h.total }
+
+// Min returns the smallest recorded value, or 0 if none were recorded.
+func (h *Histogram) Min() int64 {
+	if h.total == 0 {
+		return 0
+	}
+	return h.min
+}
+
+// Max returns the largest recorded value.
+func (h *Histogram) Max() int64 { return h.max }
+
+// Mean returns the mean of the recorded values.
+func (h *Histogram) Mean() float64 {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / float64(h.total)
+}
+
+// Percentile returns the value at percentile p, between 0 and 100, to within
+// the precision of the histogram.
+func (h *Histogram) Percentile(p float64) int64 {
+	if h.total == 0 {
+		return 0
+	}
+	if p > 100 {
+		p = 100
+	}
+	target := int64(math.Ceil(p / 100 * float64(h.total)))
+	if target < 1 {
+		target = 1
+	}
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= target {
+			v := h.valueAt(i)
+			if v > h.max {
+				v = h.max
+			}
+			if v < h.min {
+				v = h.min
+			}
+			return v
+		}
+	}
+	return h.max
+}
@@ -0,0 +1,42 @@
+package hdr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	h := New(1e10, 3)
+	for v := int64(1); v <= 10000; v++ {
+		h.Record(v)
+	}
+	assert.Equal(t, int64(10000), h.Count())
+	assert.Equal(t, int64(1), h.Min())
+	assert.Equal(t, int64(10000), h.Max())
+	assert.InDelta(t, 5000, h.Percentile(50), 5000*0.001)
+	assert.InDelta(t, 9900, h.Percentile(99), 9900*0.001)
+	assert.InDelta(t, 9990, h.Percentile(99.9), 9990*0.001)
+	assert.Equal(t, int64(10000), h.Percentile(100))
+	assert.InDelta(t, 5000.5, h.Mean(), 1e-9)
+}
+
+func TestLargeValues(t *testing.T) {
+	h := New(1e10, 2)
+	h.Record(123456789)
+	h.Record(2e10)
+	assert.InDelta(t, 123456789, h.Percentile(50), 123456789*0.01)
+	assert.Equal(t, int64(1e10), h.Max())
+}
+
+func TestMerge(t *testing.T) {
+	a, b := New(1e6, 2), New(1e6, 2)
+	a.Record(10)
+	b.Record(1000)
+	b.Record(100000)
+	a.Merge(b)
+	assert.Equal(t, int64(3), a.Count())
+	assert.Equal(t, int64(10), a.Min())
+	assert.Equal(t, int64(100000), a.Max())
+	assert.InDelta(t, 1000, a.Percentile(50), 1000*0.01)
+}
@@ -0,0 +1,251 @@
+// Package metadata collects information about the machine and toolchain
+// benchmark results were generated with.
+//
+// Most of the hardware information is read from /proc and /sys and is only
+// available on Linux. On other systems those fields are left empty.
+package metadata
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Cache describes a single CPU cache as seen by the first CPU.
+type Cache struct {
+	Level int    `json:"level"`
+	Type  string `json:"type"`
+	Size  string `json:"size"`
+}
+
+// Metadata describes the machine and toolchain used to run a set of
+// benchmarks.
+type Metadata struct {
+	GoVersion string `json:"go_version"`
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+	// GOAMD64 is the microarchitecture level the benchmarks were compiled
+	// for, only set on amd64.
+	GOAMD64    string `json:"goamd64,omitempty"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+
+	CPUModel string `json:"cpu_model,omitempty"`
+	// Cores is the number of physical cores and Threads the number of
+	// logical CPUs.
+	Cores         int     `json:"cores,omitempty"`
+	Threads       int     `json:"threads"`
+	Caches        []Cache `json:"caches,omitempty"`
+	CacheLineSize int     `json:"cache_line_size,omitempty"`
+	Governor      string  `json:"cpu_governor,omitempty"`
+
+	Kernel string `json:"kernel,omitempty"`
+	// CPUQuota is the number of CPUs the cgroup of the current process may
+	// use, or zero if it is unlimited or unknown.
+	CPUQuota float64 `json:"cgroup_cpu_quota,omitempty"`
+}
+
+// Collect gathers metadata about the current machine and the go command
+// found in PATH.
+func Collect() (*Metadata, error) {
+	env, err := goEnv("GOVERSION", "GOOS", "GOARCH", "GOAMD64")
+	if err != nil {
+		return nil, err
+	}
+	m := &Metadata{
+		GoVersion:  env["GOVERSION"],
+		GOOS:       env["GOOS"],
+		GOARCH:     env["GOARCH"],
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Threads:    runtime.NumCPU(),
+		Kernel:     readString("/proc/sys/kernel/osrelease"),
+		Governor:   readString("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor"),
+		CPUQuota:   cpuQuota(),
+	}
+	if m.GOARCH == "amd64" {
+		m.GOAMD64 = env["GOAMD64"]
+	}
+	m.readCPUInfo("/proc/cpuinfo")
+	m.readCaches("/sys/devices/system/cpu/cpu0/cache")
+	return m, nil
+}
+
+// FromConfig builds the metadata of saved go test -bench output from its
+// goos, goarch and cpu header lines, and from a go line if the output has
+// one. Everything else about the machine which ran it is unknown.
+func FromConfig(config map[string]string) *Metadata {
+	return &Metadata{
+		GoVersion: config["go"],
+		GOOS:      config["goos"],
+		GOARCH:    config["goarch"],
+		CPUModel:  config["cpu"],
+	}
+}
+
+// String returns a one line summary of m, e.g.
+//
+//	go1.9.3 linux/amd64 GOAMD64=v1 GOMAXPROCS=8, Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz (4 cores, 8 threads, L1d 32K, L2 256K, L3 8192K, 64B lines)
+func (m *Metadata) String() string {
+	var b strings.Builder
+	if m.GoVersion != "" {
+		fmt.Fprintf(&b, "%s ", m.GoVersion)
+	}
+	fmt.Fprintf(&b, "%s/%s", m.GOOS, m.GOARCH)
+	if m.GOAMD64 != "" {
+		fmt.Fprintf(&b, " GOAMD64=%s", m.GOAMD64)
+	}
+	fmt.Fprintf(&b, " GOMAXPROCS=%d", m.GOMAXPROCS)
+	if m.CPUModel != "" {
+		fmt.Fprintf(&b, ", %s", m.CPUModel)
+	}
+
+	var details []string
+	if m.Cores > 0 {
+		details = append(details, fmt.Sprintf("%d cores", m.Cores))
+	}
+	if m.Threads > 0 {
+		details = append(details, fmt.Sprintf("%d threads", m.Threads))
+	}
+	for _, c := range m.Caches {
+		if c.Type == "Instruction" {
+			continue
+		}
+		name := fmt.Sprintf("L%d", c.Level)
+		if c.Type == "Data" {
+			name += "d"
+		}
+		details = append(details, name+" "+c.Size)
+	}
+	if m.CacheLineSize > 0 {
+		details = append(details, fmt.Sprintf("%dB lines", m.CacheLineSize))
+	}
+	if m.Governor != "" {
+		details = append(details, m.Governor+" governor")
+	}
+	if m.CPUQuota > 0 {
+		details = append(details, fmt.Sprintf("cgroup quota %g CPUs", m.CPUQuota))
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+	}
+	if m.Kernel != "" {
+		fmt.Fprintf(&b, ", kernel %s", m.Kernel)
+	}
+	return b.String()
+}
+
+// goEnv returns the values of the given go env variables.
+func goEnv(keys ...string) (map[string]string, error) {
+	out, err := exec.Command("go", append([]string{"env"}, keys...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("metadata: go env: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	env := make(map[string]string, len(keys))
+	for i, key := range keys {
+		if i < len(lines) {
+			env[key] = strings.TrimSpace(lines[i])
+		}
+	}
+	return env, nil
+}
+
+// readCPUInfo fills in the CPU model and physical core count from
+// /proc/cpuinfo.
+func (m *Metadata) readCPUInfo(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var physical string
+	cores := make(map[string]bool)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		kv := strings.SplitN(sc.Text(), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "model name":
+			if m.CPUModel == "" {
+				m.CPUModel = value
+			}
+		case "physical id":
+			physical = value
+		case "core id":
+			cores[physical+"/"+value] = true
+		}
+	}
+	m.Cores = len(cores)
+}
+
+// readCaches fills in the caches of the first CPU from sysfs.
+func (m *Metadata) readCaches(dir string) {
+	paths, err := filepath.Glob(filepath.Join(dir, "index*"))
+	if err != nil {
+		return
+	}
+	for _, path := range paths {
+		level, err := strconv.Atoi(readString(filepath.Join(path, "level")))
+		if err != nil {
+			continue
+		}
+		m.Caches = append(m.Caches, Cache{
+			Level: level,
+			Type:  readString(filepath.Join(path, "type")),
+			Size:  readString(filepath.Join(path, "size")),
+		})
+		if m.CacheLineSize == 0 {
+			m.CacheLineSize, _ = strconv.Atoi(readString(filepath.Join(path, "coherency_line_size")))
+		}
+	}
+	sort.SliceStable(m.Caches, func(i, j int) bool {
+		return m.Caches[i].Level < m.Caches[j].Level
+	})
+}
+
+// cpuQuota returns the number of CPUs the current cgroup is limited to, or
+// zero if there is no limit.
+func cpuQuota() float64 {
+	// cgroup v2 exposes "$MAX $PERIOD" where $MAX may be "max".
+	if fields := strings.Fields(readString("/sys/fs/cgroup/cpu.max")); len(fields) == 2 {
+		return ratio(fields[0], fields[1])
+	}
+	// cgroup v1 exposes the quota and period separately, with a quota of -1
+	// meaning unlimited.
+	return ratio(
+		readString("/sys/fs/cgroup/cpu/cpu.cfs_quota_us"),
+		readString("/sys/fs/cgroup/cpu/cpu.cfs_period_us"),
+	)
+}
+
+func ratio(quota, period string) float64 {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0
+	}
+	return q / p
+}
+
+// readString returns the trimmed contents of the file at path or the empty
+// string if it can't be read.
+func readString(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
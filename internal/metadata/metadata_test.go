@@ -0,0 +1,55 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleCPUInfo = `processor	: 0
+model name	: Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz
+physical id	: 0
+core id		: 0
+
+processor	: 1
+model name	: Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz
+physical id	: 0
+core id		: 1
+
+processor	: 2
+model name	: Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz
+physical id	: 0
+core id		: 0
+`
+
+func TestReadCPUInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metadata")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cpuinfo")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(sampleCPUInfo), 0644))
+
+	var m Metadata
+	m.readCPUInfo(path)
+	assert.Equal(t, "Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz", m.CPUModel)
+	assert.Equal(t, 2, m.Cores)
+}
+
+func TestRatio(t *testing.T) {
+	assert.Equal(t, 2.0, ratio("200000", "100000"))
+	assert.Equal(t, 0.0, ratio("max", "100000"))
+	assert.Equal(t, 0.0, ratio("-1", "100000"))
+}
+
+func TestFromConfig(t *testing.T) {
+	m := FromConfig(map[string]string{"goos": "darwin", "goarch": "arm64", "cpu": "Apple M1", "pkg": "github.com/jeromefroe/golang_benchmarks/sync"})
+	m.GOMAXPROCS = 8
+	assert.Equal(t, "darwin/arm64 GOMAXPROCS=8, Apple M1", m.String())
+
+	m.GoVersion = "go1.23.4"
+	assert.Equal(t, "go1.23.4 darwin/arm64 GOMAXPROCS=8, Apple M1", m.String())
+}
@@ -0,0 +1,135 @@
+// Package microarch compares benchmark results across GOAMD64
+// microarchitecture levels, which decide whether the compiler may emit
+// instructions such as POPCNT (v2), or BMI2 and MULX (v3).
+package microarch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+)
+
+// Levels are the valid values of GOAMD64, in increasing order.
+var Levels = []string{"v1", "v2", "v3", "v4"}
+
+// ParseLevels parses a comma separated list of GOAMD64 levels and returns
+// them in increasing order.
+func ParseLevels(s string) ([]string, error) {
+	want := make(map[string]bool)
+	for _, l := range strings.Split(s, ",") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if !valid(l) {
+			return nil, fmt.Errorf("unknown GOAMD64 level %q, want one of %s", l, strings.Join(Levels, ", "))
+		}
+		want[l] = true
+	}
+	var levels []string
+	for _, l := range Levels {
+		if want[l] {
+			levels = append(levels, l)
+		}
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no GOAMD64 levels in %q", s)
+	}
+	return levels, nil
+}
+
+func valid(level string) bool {
+	for _, l := range Levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// Unsupported reports whether output is the error the Go runtime prints when
+// a binary is built for a GOAMD64 level which the CPU can't execute.
+func Unsupported(output []byte) bool {
+	return bytes.Contains(output, []byte("microarchitecture support"))
+}
+
+// Row is the median ns/op of a single benchmark at each level.
+type Row struct {
+	Name string
+	// NsPerOp is indexed like the levels the row was built from and is zero
+	// where the benchmark has no results.
+	NsPerOp []float64
+}
+
+// Speedup returns how many times faster the benchmark ran at the i'th level
+// than at the first, or zero if either is missing.
+func (r *Row) Speedup(i int) float64 {
+	if r.NsPerOp[0] == 0 || r.NsPerOp[i] == 0 {
+		return 0
+	}
+	return r.NsPerOp[0] / r.NsPerOp[i]
+}
+
+// Rows builds a row for every benchmark in sets, where sets[i] holds the
+// results at the i'th level, in the order the benchmarks first appear.
+// Results sampled several times are reduced to their median.
+func Rows(sets []*bench.Set) []*Row {
+	var rows []*Row
+	byName := make(map[string]*Row)
+	for i, set := range sets {
+		if set == nil {
+			continue
+		}
+		for _, r := range set.Results {
+			row := byName[r.Name]
+			if row == nil {
+				row = &Row{Name: r.Name, NsPerOp: make([]float64, len(sets))}
+				byName[r.Name] = row
+				rows = append(rows, row)
+			}
+			if row.NsPerOp[i] == 0 {
+				row.NsPerOp[i] = compare.Samples(set, r.Name).Median()
+			}
+		}
+	}
+	return rows
+}
+
+// WriteTable writes the median ns/op of each row at each level followed by
+// the speedup of every level over the first. With fewer than two levels
+// there are no speedups to write.
+func WriteTable(w io.Writer, levels []string, rows []*Row) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "benchmark\t")
+	for _, l := range levels {
+		fmt.Fprintf(tw, "%s ns/op\t", l)
+	}
+	for i := 1; i < len(levels); i++ {
+		fmt.Fprintf(tw, "%s/%s\t", levels[i], levels[0])
+	}
+	fmt.Fprintln(tw)
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t", r.Name)
+		for _, v := range r.NsPerOp {
+			if v == 0 {
+				fmt.Fprintf(tw, "-\t")
+			} else {
+				fmt.Fprintf(tw, "%s\t", bench.FormatValue(v))
+			}
+		}
+		for i := 1; i < len(levels); i++ {
+			if s := r.Speedup(i); s == 0 {
+				fmt.Fprintf(tw, "-\t")
+			} else {
+				fmt.Fprintf(tw, "%.2fx\t", s)
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
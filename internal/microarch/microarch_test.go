@@ -0,0 +1,65 @@
+package microarch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("v3, v1,v2,v1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v2", "v3"}, levels)
+
+	_, err = ParseLevels("v5")
+	assert.Error(t, err)
+	_, err = ParseLevels("")
+	assert.Error(t, err)
+}
+
+func TestUnsupported(t *testing.T) {
+	assert.True(t, Unsupported([]byte("This program can only be run on AMD64 processors with v4 microarchitecture support.\n")))
+	assert.False(t, Unsupported([]byte("PASS\n")))
+}
+
+func TestRows(t *testing.T) {
+	v1 := &bench.Set{Results: []*bench.Result{
+		{Name: "BenchmarkHash64Xxhash", NsPerOp: 10},
+		{Name: "BenchmarkHash64Xxhash", NsPerOp: 12},
+		{Name: "BenchmarkBitTricksAnd", NsPerOp: 1},
+	}}
+	v3 := &bench.Set{Results: []*bench.Result{
+		{Name: "BenchmarkHash64Xxhash", NsPerOp: 5.5},
+	}}
+	rows := Rows([]*bench.Set{v1, nil, v3})
+	assert.Len(t, rows, 2)
+
+	xxhash := rows[0]
+	assert.Equal(t, []float64{11, 0, 5.5}, xxhash.NsPerOp)
+	assert.InDelta(t, 2, xxhash.Speedup(2), 1e-9)
+	assert.Equal(t, 0.0, xxhash.Speedup(1))
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteTable(&buf, []string{"v1", "v2", "v3"}, rows))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "v3/v1")
+	assert.Contains(t, lines[1], "2.00x")
+}
+
+func TestWriteTableFewLevels(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteTable(&buf, nil, nil))
+	assert.Equal(t, "benchmark", strings.TrimSpace(buf.String()))
+
+	buf.Reset()
+	rows := []*Row{{Name: "BenchmarkBitTricksAnd", NsPerOp: []float64{1}}}
+	assert.NoError(t, WriteTable(&buf, []string{"v1"}, rows))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.NotContains(t, lines[0], "/v1")
+	assert.Contains(t, lines[1], "1")
+}
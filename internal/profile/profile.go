@@ -0,0 +1,408 @@
+// Package profile parses the pprof profiles written by go test -cpuprofile
+// and -memprofile and summarizes the functions the samples are spent in.
+//
+// Only the parts of the profile.proto format needed to attribute sample
+// values to functions are decoded, so the repository doesn't need to depend
+// on the pprof tool.
+package profile
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// ValueType describes a value recorded for each sample, e.g. cpu in
+// nanoseconds or alloc_space in bytes.
+type ValueType struct {
+	Type, Unit string
+}
+
+// Sample is a stack with the values recorded for it.
+type Sample struct {
+	// Stack lists the functions on the stack, innermost first. Inlined
+	// functions appear as frames of their own.
+	Stack  []string
+	Values []int64
+}
+
+// Profile is a parsed profile.
+type Profile struct {
+	SampleTypes []ValueType
+	Samples     []Sample
+}
+
+// Read parses the profile at path.
+func Read(path string) (*Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// Parse parses a profile, which may be gzip compressed as the runtime writes
+// them.
+func Parse(r io.Reader) (*Profile, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// The field numbers of the messages in profile.proto which are decoded.
+const (
+	profileSampleType  = 1
+	profileSample      = 2
+	profileLocation    = 4
+	profileFunction    = 5
+	profileStringTable = 6
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+
+	functionID   = 1
+	functionName = 2
+)
+
+type rawSample struct {
+	locations []uint64
+	values    []int64
+}
+
+// decode decodes an uncompressed profile. String table indexes and location
+// and function ids can only be resolved once the whole message has been read.
+func decode(data []byte) (*Profile, error) {
+	var (
+		strs       []string
+		types      [][2]int64
+		samples    []rawSample
+		locations  = make(map[uint64][]uint64)
+		funcsNames = make(map[uint64]int64)
+	)
+	err := fields(data, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case profileStringTable:
+			strs = append(strs, string(b))
+		case profileSampleType:
+			var t [2]int64
+			err := fields(b, func(num int, _ int, v uint64, _ []byte) error {
+				switch num {
+				case valueTypeType:
+					t[0] = int64(v)
+				case valueTypeUnit:
+					t[1] = int64(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			types = append(types, t)
+		case profileSample:
+			var s rawSample
+			err := fields(b, func(num int, wire int, v uint64, b []byte) error {
+				switch num {
+				case sampleLocationID:
+					return repeated(wire, v, b, func(v uint64) { s.locations = append(s.locations, v) })
+				case sampleValue:
+					return repeated(wire, v, b, func(v uint64) { s.values = append(s.values, int64(v)) })
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			samples = append(samples, s)
+		case profileLocation:
+			var (
+				id    uint64
+				funcs []uint64
+			)
+			err := fields(b, func(num int, _ int, v uint64, b []byte) error {
+				switch num {
+				case locationID:
+					id = v
+				case locationLine:
+					return fields(b, func(num int, _ int, v uint64, _ []byte) error {
+						if num == lineFunctionID {
+							funcs = append(funcs, v)
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			locations[id] = funcs
+		case profileFunction:
+			var id uint64
+			var name int64
+			err := fields(b, func(num int, _ int, v uint64, _ []byte) error {
+				switch num {
+				case functionID:
+					id = v
+				case functionName:
+					name = int64(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			funcsNames[id] = name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	str := func(i int64) string {
+		if i < 0 || i >= int64(len(strs)) {
+			return ""
+		}
+		return strs[i]
+	}
+	p := &Profile{}
+	for _, t := range types {
+		p.SampleTypes = append(p.SampleTypes, ValueType{Type: str(t[0]), Unit: str(t[1])})
+	}
+	for _, s := range samples {
+		sample := Sample{Values: s.values}
+		for _, loc := range s.locations {
+			for _, fn := range locations[loc] {
+				sample.Stack = append(sample.Stack, str(funcsNames[fn]))
+			}
+		}
+		p.Samples = append(p.Samples, sample)
+	}
+	return p, nil
+}
+
+var errTruncated = errors.New("profile: truncated message")
+
+// fields calls fn for each field of the protobuf message in data with its
+// field number and wire type, and either its value, for varint and fixed
+// width fields, or its bytes, for length delimited ones.
+func fields(data []byte, fn func(num int, wire int, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		key, n := varint(data)
+		if n == 0 {
+			return errTruncated
+		}
+		data = data[n:]
+		num, wire := int(key>>3), int(key&7)
+
+		var (
+			v uint64
+			b []byte
+		)
+		switch wire {
+		case 0:
+			v, n = varint(data)
+			if n == 0 {
+				return errTruncated
+			}
+			data = data[n:]
+		case 1:
+			if len(data) < 8 {
+				return errTruncated
+			}
+			data = data[8:]
+		case 2:
+			l, n := varint(data)
+			if n == 0 || uint64(len(data)-n) < l {
+				return errTruncated
+			}
+			b = data[n : n+int(l)]
+			data = data[n+int(l):]
+		case 5:
+			if len(data) < 4 {
+				return errTruncated
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("profile: unsupported wire type %d", wire)
+		}
+		if err := fn(num, wire, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repeated decodes a repeated varint field, which may be packed.
+func repeated(wire int, v uint64, b []byte, fn func(uint64)) error {
+	if wire == 0 {
+		fn(v)
+		return nil
+	}
+	for len(b) > 0 {
+		v, n := varint(b)
+		if n == 0 {
+			return errTruncated
+		}
+		fn(v)
+		b = b[n:]
+	}
+	return nil
+}
+
+// varint decodes a varint from the start of b and returns it with the
+// number of bytes read, or zero if b is truncated.
+func varint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * uint(i))
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// Index returns the index of the sample values of the given type, e.g. cpu
+// or alloc_space, or -1 if the profile doesn't record it.
+func (p *Profile) Index(typ string) int {
+	for i, t := range p.SampleTypes {
+		if t.Type == typ {
+			return i
+		}
+	}
+	return -1
+}
+
+// Entry is a function and the sample values spent in it.
+type Entry struct {
+	Func string
+	// Flat is the value of the samples in which the function is
+	// innermost and Cum of those in which it is anywhere on the stack.
+	Flat, Cum int64
+}
+
+// Top returns the n functions with the largest flat values of the sample
+// type at index i, and the total of all samples.
+func (p *Profile) Top(i, n int) ([]Entry, int64) {
+	byFunc := make(map[string]*Entry)
+	entry := func(name string) *Entry {
+		e := byFunc[name]
+		if e == nil {
+			e = &Entry{Func: name}
+			byFunc[name] = e
+		}
+		return e
+	}
+
+	var total int64
+	for _, s := range p.Samples {
+		if i >= len(s.Values) || len(s.Stack) == 0 {
+			continue
+		}
+		v := s.Values[i]
+		total += v
+		entry(s.Stack[0]).Flat += v
+		seen := make(map[string]bool, len(s.Stack))
+		for _, fn := range s.Stack {
+			if !seen[fn] {
+				seen[fn] = true
+				entry(fn).Cum += v
+			}
+		}
+	}
+
+	entries := make([]Entry, 0, len(byFunc))
+	for _, e := range byFunc {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].Flat != entries[b].Flat {
+			return entries[a].Flat > entries[b].Flat
+		}
+		if entries[a].Cum != entries[b].Cum {
+			return entries[a].Cum > entries[b].Cum
+		}
+		return entries[a].Func < entries[b].Func
+	})
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries, total
+}
+
+// WriteTop writes entries as a table headed by the sample type and total,
+// formatting values according to unit.
+func WriteTop(w io.Writer, typ ValueType, entries []Entry, total int64) error {
+	format := func(v int64) string {
+		switch typ.Unit {
+		case "nanoseconds":
+			return time.Duration(v).Round(10 * time.Microsecond).String()
+		case "bytes":
+			return formatBytes(v)
+		}
+		return strconv.FormatInt(v, 10)
+	}
+	percent := func(v int64) string {
+		if total == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", 100*float64(v)/float64(total))
+	}
+
+	fmt.Fprintf(w, "%s (total %s)\n", typ.Type, format(total))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "flat\tflat%\tcum\tcum%\t\tfunction")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\t%s\n", format(e.Flat), percent(e.Flat), format(e.Cum), percent(e.Cum), e.Func)
+	}
+	return tw.Flush()
+}
+
+// formatBytes formats n bytes with a binary unit.
+func formatBytes(n int64) string {
+	const units = "KMGT"
+	if n < 1024 && n > -1024 {
+		return fmt.Sprintf("%dB", n)
+	}
+	v, i := float64(n)/1024, 0
+	for (v >= 1024 || v <= -1024) && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f%cB", v, units[i])
+}
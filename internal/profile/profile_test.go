@@ -0,0 +1,133 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var sampleProfile = &Profile{
+	SampleTypes: []ValueType{{"samples", "count"}, {"cpu", "nanoseconds"}},
+	Samples: []Sample{
+		{Stack: []string{"runtime.lock2", "runtime.chansend", "main.BenchmarkChannelMPMC.func1"}, Values: []int64{3, 300}},
+		{Stack: []string{"runtime.gopark", "runtime.chanrecv", "main.BenchmarkChannelMPMC.func2"}, Values: []int64{2, 200}},
+		{Stack: []string{"runtime.lock2", "runtime.chanrecv", "main.BenchmarkChannelMPMC.func2"}, Values: []int64{1, 100}},
+		{Stack: []string{"runtime.procyield", "runtime.lock2", "runtime.chansend"}, Values: []int64{1, 50}},
+	},
+}
+
+func TestParse(t *testing.T) {
+	var raw bytes.Buffer
+	assert.NoError(t, encode(sampleProfile, &raw))
+	p, err := Parse(bytes.NewReader(raw.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, sampleProfile, p)
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(raw.Bytes())
+	zw.Close()
+	p, err = Parse(&compressed)
+	assert.NoError(t, err)
+	assert.Equal(t, sampleProfile, p)
+
+	_, err = Parse(bytes.NewReader(raw.Bytes()[:raw.Len()-3]))
+	assert.Error(t, err)
+}
+
+func TestTop(t *testing.T) {
+	i := sampleProfile.Index("cpu")
+	assert.Equal(t, 1, i)
+	assert.Equal(t, -1, sampleProfile.Index("alloc_space"))
+
+	top, total := sampleProfile.Top(i, 3)
+	assert.Equal(t, int64(650), total)
+	assert.Equal(t, []Entry{
+		{Func: "runtime.lock2", Flat: 400, Cum: 450},
+		{Func: "runtime.gopark", Flat: 200, Cum: 200},
+		{Func: "runtime.procyield", Flat: 50, Cum: 50},
+	}, top)
+}
+
+// encode writes p as an uncompressed profile. Only the parts of the format
+// which Parse decodes are written.
+func encode(p *Profile, w io.Writer) error {
+	var buf bytes.Buffer
+	strs := map[string]int{"": 0}
+	table := []string{""}
+	str := func(s string) uint64 {
+		i, ok := strs[s]
+		if !ok {
+			i = len(table)
+			strs[s] = i
+			table = append(table, s)
+		}
+		return uint64(i)
+	}
+	funcs := make(map[string]uint64)
+	var funcOrder []string
+
+	for _, t := range p.SampleTypes {
+		var m bytes.Buffer
+		putVarintField(&m, valueTypeType, str(t.Type))
+		putVarintField(&m, valueTypeUnit, str(t.Unit))
+		putBytesField(&buf, profileSampleType, m.Bytes())
+	}
+	for _, s := range p.Samples {
+		var m, locs, vals bytes.Buffer
+		for _, fn := range s.Stack {
+			id, ok := funcs[fn]
+			if !ok {
+				id = uint64(len(funcs) + 1)
+				funcs[fn] = id
+				funcOrder = append(funcOrder, fn)
+			}
+			putVarint(&locs, id)
+		}
+		for _, v := range s.Values {
+			putVarint(&vals, uint64(v))
+		}
+		putBytesField(&m, sampleLocationID, locs.Bytes())
+		putBytesField(&m, sampleValue, vals.Bytes())
+		putBytesField(&buf, profileSample, m.Bytes())
+	}
+	// Each function gets a location of its own with the same id.
+	for _, fn := range funcOrder {
+		id := funcs[fn]
+		var loc, line, f bytes.Buffer
+		putVarintField(&line, lineFunctionID, id)
+		putVarintField(&loc, locationID, id)
+		putBytesField(&loc, locationLine, line.Bytes())
+		putBytesField(&buf, profileLocation, loc.Bytes())
+		putVarintField(&f, functionID, id)
+		putVarintField(&f, functionName, str(fn))
+		putBytesField(&buf, profileFunction, f.Bytes())
+	}
+	for _, s := range table {
+		putBytesField(&buf, profileStringTable, []byte(s))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func putVarint(buf *bytes.Buffer, v uint64) {
+	for v >= 0x80 {
+		buf.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	buf.WriteByte(byte(v))
+}
+
+func putVarintField(buf *bytes.Buffer, num int, v uint64) {
+	putVarint(buf, uint64(num)<<3)
+	putVarint(buf, v)
+}
+
+func putBytesField(buf *bytes.Buffer, num int, b []byte) {
+	putVarint(buf, uint64(num)<<3|2)
+	putVarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
@@ -0,0 +1,179 @@
+// Package report renders benchmark results as a self-contained HTML page.
+//
+// Charts are drawn as inline SVG so the page has no external dependencies
+// and can be opened straight from disk or attached to an issue.
+package report
+
+import (
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+	"github.com/jeromefroe/golang_benchmarks/internal/escape"
+	"github.com/jeromefroe/golang_benchmarks/internal/results"
+)
+
+// Section is a rendered topic.
+type Section struct {
+	Title  string
+	Charts []template.HTML
+}
+
+// Page is the data the report template is executed with.
+type Page struct {
+	Title    string
+	Machine  string
+	Sections []Section
+	// Escapes, if set with AttachEscapes, is rendered as a table after
+	// the charts.
+	Escapes []escape.Summary
+}
+
+// Build groups the results of run by topic and renders a chart for each
+// group. Benchmarks sampled several times are shown by their median ns/op.
+func Build(run *results.Run) *Page {
+	var names []string
+	samples := make(map[string]compare.Sample)
+	for _, r := range run.Benchmarks {
+		if _, ok := samples[r.Name]; !ok {
+			names = append(names, r.Name)
+		}
+		samples[r.Name] = append(samples[r.Name], r.NsPerOp)
+	}
+
+	page := &Page{Title: "Golang Benchmarks"}
+	if run.Metadata != nil {
+		page.Machine = run.Metadata.String()
+	}
+
+	used := make(map[string]bool)
+	for _, t := range Topics {
+		s := Section{Title: t.Title}
+		for _, sw := range t.Sweeps {
+			if chart := sweepChart(sw, names, samples, used); chart != "" {
+				s.Charts = append(s.Charts, chart)
+			}
+		}
+		if t.Bars != nil {
+			var bars []Bar
+			for _, name := range names {
+				if t.Bars.MatchString(name) && !used[name] {
+					used[name] = true
+					bars = append(bars, Bar{Label: name, Value: samples[name].Median()})
+				}
+			}
+			if len(bars) > 0 {
+				s.Charts = append([]template.HTML{template.HTML(BarChart(bars, "ns/op"))}, s.Charts...)
+			}
+		}
+		if len(s.Charts) > 0 {
+			page.Sections = append(page.Sections, s)
+		}
+	}
+
+	var other []Bar
+	for _, name := range names {
+		if !used[name] {
+			other = append(other, Bar{Label: name, Value: samples[name].Median()})
+		}
+	}
+	if len(other) > 0 {
+		page.Sections = append(page.Sections, Section{
+			Title:  "Other",
+			Charts: []template.HTML{template.HTML(BarChart(other, "ns/op"))},
+		})
+	}
+	return page
+}
+
+// sweepChart renders the benchmarks matching sw as a line chart, marking
+// them as used.
+func sweepChart(sw Sweep, names []string, samples map[string]compare.Sample, used map[string]bool) template.HTML {
+	byName := make(map[string]*Series)
+	var series []*Series
+	si, xi := sw.Pattern.SubexpIndex("series"), sw.Pattern.SubexpIndex("x")
+	for _, name := range names {
+		m := sw.Pattern.FindStringSubmatch(name)
+		if m == nil || used[name] {
+			continue
+		}
+		x, err := bench.ParseSize(m[xi])
+		if err != nil {
+			continue
+		}
+		used[name] = true
+		s := byName[m[si]]
+		if s == nil {
+			s = &Series{Name: m[si]}
+			byName[m[si]] = s
+			series = append(series, s)
+		}
+		s.Points = append(s.Points, Point{X: float64(x), Y: samples[name].Median()})
+	}
+	if len(series) == 0 {
+		return ""
+	}
+	sort.SliceStable(series, func(i, j int) bool { return series[i].Name < series[j].Name })
+	values := make([]Series, len(series))
+	for i, s := range series {
+		values[i] = *s
+	}
+	return template.HTML(LineChart(values, sw.XLabel, "ns/op"))
+}
+
+// AttachEscapes adds the escape analysis and inlining summaries of the
+// benchmarks to the page.
+func (p *Page) AttachEscapes(summaries []escape.Summary) {
+	p.Escapes = summaries
+}
+
+// Write renders p as HTML to w.
+func (p *Page) Write(w io.Writer) error {
+	return pageTemplate.Execute(w, p)
+}
+
+var pageTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 1000px; color: #222; }
+nav a { margin-right: 1em; }
+section { margin-top: 2em; }
+svg { display: block; margin: 1em 0; }
+.machine { color: #666; font-family: monospace; }
+table { border-collapse: collapse; font-size: 0.9em; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+td ul { margin: 0; padding-left: 1.2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Machine}}<p class="machine">{{.Machine}}</p>{{end}}
+<nav>{{range $i, $s := .Sections}}<a href="#section-{{$i}}">{{$s.Title}}</a>{{end}}{{if .Escapes}}<a href="#escapes">Escape Analysis</a>{{end}}</nav>
+{{range $i, $s := .Sections}}
+<section id="section-{{$i}}">
+<h2>{{$s.Title}}</h2>
+{{range $s.Charts}}{{.}}
+{{end}}</section>
+{{end}}
+{{if .Escapes}}
+<section id="escapes">
+<h2>Escape Analysis</h2>
+<table>
+<tr><th>Benchmark</th><th>Moved to heap</th><th>Inlined calls</th><th>Not inlined</th></tr>
+{{range .Escapes}}<tr>
+<td>{{.Benchmark}}<br><span class="machine">{{.File}}:{{.Line}}</span></td>
+<td><ul>{{range .Heap}}<li>{{.Subject}} <span class="machine">{{.Func}}:{{.Line}}</span></li>{{end}}</ul></td>
+<td><ul>{{range .Inlined}}<li>{{.}}</li>{{end}}</ul></td>
+<td><ul>{{range .NotInlined}}<li>{{.Subject}}: {{.Reason}}</li>{{end}}</ul></td>
+</tr>
+{{end}}</table>
+</section>
+{{end}}
+</body>
+</html>
+`))
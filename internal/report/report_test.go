@@ -0,0 +1,32 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/results"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	run := &results.Run{Benchmarks: []*bench.Result{
+		{Name: "BenchmarkAtomicLoad32", NsPerOp: 1.77},
+		{Name: "BenchmarkSliceClearZero/len=1K", NsPerOp: 12.9},
+		{Name: "BenchmarkSliceClearZero/len=16K", NsPerOp: 167},
+		{Name: "BenchmarkTypeAssertion", NsPerOp: 0.97},
+	}}
+	page := Build(run)
+
+	var titles []string
+	for _, s := range page.Sections {
+		titles = append(titles, s.Title)
+	}
+	assert.Equal(t, []string{"Atomic Operations", "Memset", "Other"}, titles)
+
+	var buf bytes.Buffer
+	assert.NoError(t, page.Write(&buf))
+	assert.Equal(t, 3, strings.Count(buf.String(), "<svg"))
+	assert.False(t, strings.Contains(buf.String(), "<script"))
+}
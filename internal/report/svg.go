@@ -0,0 +1,155 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+)
+
+var palette = []string{"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7"}
+
+// Bar is a single bar of a bar chart.
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// BarChart renders horizontal bars, one per benchmark, as an inline SVG.
+func BarChart(bars []Bar, unit string) string {
+	const (
+		rowHeight = 22
+		barHeight = 16
+		plotWidth = 420
+		charWidth = 7
+	)
+	var maxLabel int
+	var maxValue float64
+	for _, b := range bars {
+		if len(b.Label) > maxLabel {
+			maxLabel = len(b.Label)
+		}
+		maxValue = math.Max(maxValue, b.Value)
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+	left := maxLabel*charWidth + 10
+	width := left + plotWidth + 120
+	height := len(bars)*rowHeight + 10
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`, width, height)
+	for i, b := range bars {
+		y := 5 + i*rowHeight
+		w := b.Value / maxValue * plotWidth
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="end">%s</text>`, left-6, y+barHeight-4, html.EscapeString(b.Label))
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s"><title>%s: %s %s</title></rect>`,
+			left, y, w, barHeight, palette[0], html.EscapeString(b.Label), bench.FormatValue(b.Value), unit)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%d">%s %s</text>`, float64(left)+w+4, y+barHeight-4, bench.FormatValue(b.Value), unit)
+	}
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
+
+// Point is a single point of a line chart.
+type Point struct {
+	X, Y float64
+}
+
+// Series is a named line of a line chart.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// LineChart renders the series on logarithmic axes as an inline SVG, which
+// suits sweeps over sizes spanning several orders of magnitude.
+func LineChart(series []Series, xLabel, yLabel string) string {
+	const (
+		width, height            = 640, 340
+		left, right, top, bottom = 70, 170, 15, 45
+	)
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, p := range s.Points {
+			if p.X <= 0 || p.Y <= 0 {
+				continue
+			}
+			minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+			minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return ""
+	}
+	// Extend the axes to whole decades so ticks fall on powers of ten.
+	lx0, lx1 := math.Floor(math.Log10(minX)), math.Ceil(math.Log10(maxX))
+	ly0, ly1 := math.Floor(math.Log10(minY)), math.Ceil(math.Log10(maxY))
+	if lx0 == lx1 {
+		lx1++
+	}
+	if ly0 == ly1 {
+		ly1++
+	}
+	pw, ph := float64(width-left-right), float64(height-top-bottom)
+	px := func(x float64) float64 { return left + (math.Log10(x)-lx0)/(lx1-lx0)*pw }
+	py := func(y float64) float64 { return top + ph - (math.Log10(y)-ly0)/(ly1-ly0)*ph }
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`, width, height)
+	fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%.0f" height="%.0f" fill="none" stroke="#999"/>`, left, top, pw, ph)
+	for e := lx0; e <= lx1; e++ {
+		x := px(math.Pow(10, e))
+		fmt.Fprintf(&sb, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%.1f" stroke="#ddd"/>`, x, top, x, top+ph)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, x, top+ph+16, formatPow(e))
+	}
+	for e := ly0; e <= ly1; e++ {
+		y := py(math.Pow(10, e))
+		fmt.Fprintf(&sb, `<line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`, left, y, left+pw, y)
+		fmt.Fprintf(&sb, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`, left-6, y+4, formatPow(e))
+	}
+	fmt.Fprintf(&sb, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`, left+pw/2, height-8, html.EscapeString(xLabel))
+	fmt.Fprintf(&sb, `<text x="14" y="%.1f" text-anchor="middle" transform="rotate(-90 14 %.1f)">%s</text>`, top+ph/2, top+ph/2, html.EscapeString(yLabel))
+
+	for i, s := range series {
+		color := palette[i%len(palette)]
+		points := append([]Point(nil), s.Points...)
+		sort.Slice(points, func(i, j int) bool { return points[i].X < points[j].X })
+		var path []string
+		for _, p := range points {
+			if p.X <= 0 || p.Y <= 0 {
+				continue
+			}
+			path = append(path, fmt.Sprintf("%.1f,%.1f", px(p.X), py(p.Y)))
+			fmt.Fprintf(&sb, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s %g: %s</title></circle>`,
+				px(p.X), py(p.Y), color, html.EscapeString(s.Name), p.X, bench.FormatValue(p.Y))
+		}
+		fmt.Fprintf(&sb, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(path, " "), color)
+		ly := top + 10 + i*18
+		fmt.Fprintf(&sb, `<rect x="%.1f" y="%d" width="12" height="12" fill="%s"/>`, left+pw+10, ly-10, color)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%d">%s</text>`, left+pw+26, ly, html.EscapeString(s.Name))
+	}
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
+
+// formatPow formats 10^e compactly, e.g. 1K or 10M.
+func formatPow(e float64) string {
+	switch n := int(e); {
+	case n >= 9:
+		return fmt.Sprintf("%.0fG", math.Pow(10, e-9))
+	case n >= 6:
+		return fmt.Sprintf("%.0fM", math.Pow(10, e-6))
+	case n >= 3:
+		return fmt.Sprintf("%.0fK", math.Pow(10, e-3))
+	case n >= 0:
+		return fmt.Sprintf("%.0f", math.Pow(10, e))
+	default:
+		return fmt.Sprintf("%g", math.Pow(10, e))
+	}
+}
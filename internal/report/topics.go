@@ -0,0 +1,83 @@
+package report
+
+import "regexp"
+
+// Sweep extracts a line chart from benchmarks which measure the same
+// operation over a range of sizes. Pattern must have a "series" and an "x"
+// subexpression, for example BenchmarkSliceClearZero/len=16K has the series
+// SliceClearZero and x 16K.
+type Sweep struct {
+	Pattern *regexp.Regexp
+	XLabel  string
+}
+
+// Topic is a section of the report.
+type Topic struct {
+	Title string
+	// Bars matches the benchmarks compared in a bar chart.
+	Bars *regexp.Regexp
+	// Sweeps are plotted as line charts of ns/op against size.
+	Sweeps []Sweep
+}
+
+// Topics are the sections of the report in the order they appear. Results
+// not matched by any topic are shown in a final section.
+var Topics = []Topic{
+	{
+		Title: "Allocation",
+		Bars:  regexp.MustCompile(`^BenchmarkAllocate(Foo|Bar|Slice)`),
+	},
+	{
+		Title: "Atomic Operations",
+		Bars:  regexp.MustCompile(`^BenchmarkAtomic`),
+	},
+	{
+		Title: "Bitsets",
+		Sweeps: []Sweep{{
+			Pattern: regexp.MustCompile(`^BenchmarkBitset(?P<series>\w+?)Consecutive/bits=(?P<x>\d+[KM]?)$`),
+			XLabel:  "bits set",
+		}},
+	},
+	{
+		Title: "Channels vs Ring Buffer",
+		Bars:  regexp.MustCompile(`^Benchmark((Channel|RingBuffer)(SPSC|SPMC|MPSC|MPMC)|SynchronousChannel|BufferedChannel)$`),
+	},
+	{
+		Title: "Hashing",
+		Bars:  regexp.MustCompile(`^BenchmarkHash`),
+		Sweeps: []Sweep{{
+			Pattern: regexp.MustCompile(`^BenchmarkHashSize/(?P<series>\w+)/size=(?P<x>\d+[KM]?)$`),
+			XLabel:  "input bytes",
+		}, {
+			Pattern: regexp.MustCompile(`^BenchmarkHashStream/(?P<series>\w+)/chunk=(?P<x>\d+[KM]?)$`),
+			XLabel:  "chunk bytes",
+		}},
+	},
+	{
+		Title: "Map Lookups",
+		Bars:  regexp.MustCompile(`^BenchmarkMapUint64$`),
+		Sweeps: []Sweep{{
+			Pattern: regexp.MustCompile(`^Benchmark(?P<series>MapString)/keylen=(?P<x>\d+[KM]?)$`),
+			XLabel:  "key length",
+		}},
+	},
+	{
+		Title: "Memset",
+		Sweeps: []Sweep{{
+			Pattern: regexp.MustCompile(`^Benchmark(?P<series>SliceClear\w+)/len=(?P<x>\d+[KM]?)$`),
+			XLabel:  "slice length",
+		}},
+	},
+	{
+		Title: "Locking",
+		Bars:  regexp.MustCompile(`^Benchmark(NoMutexLock|RWMutexReadLock|RWMutexLock|MutexLock|MutexUnlock|MutexDeferUnlock)$`),
+	},
+	{
+		Title: "Pools",
+		Bars:  regexp.MustCompile(`^Benchmark(AllocateBufferNoPool|ChannelBufferPool|SyncBufferPool|PoolM3X\w+|PoolSync\w+)$`),
+	},
+	{
+		Title: "Random Numbers",
+		Bars:  regexp.MustCompile(`^Benchmark(\w+Rand(Int63|Float64)|\w+BoundedRandomNumber)$`),
+	},
+}
@@ -0,0 +1,122 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+)
+
+// History is the median value of a unit for a set of benchmarks across Go
+// versions.
+type History struct {
+	Unit     string
+	Versions []string
+	Names    []string
+	// Values maps benchmark name to Go version to the median value reported
+	// across all runs with that version.
+	Values map[string]map[string]float64
+}
+
+// Query builds the history of every benchmark matching re for the given
+// unit. If from or to are non-empty, only versions in the inclusive range
+// are included. An upper bound without a patch number, such as go1.23,
+// includes every release of that minor version.
+func Query(runs []*Run, re *regexp.Regexp, unit, from, to string) *History {
+	samples := make(map[string]map[string]compare.Sample)
+	versions := make(map[string]bool)
+	for _, r := range runs {
+		v := r.GoVersion()
+		if (from != "" && CompareVersions(v, from) < 0) || (to != "" && afterBound(v, to)) {
+			continue
+		}
+		for _, b := range r.Benchmarks {
+			if !re.MatchString(b.Name) {
+				continue
+			}
+			value, ok := b.Value(unit)
+			if !ok {
+				continue
+			}
+			if samples[b.Name] == nil {
+				samples[b.Name] = make(map[string]compare.Sample)
+			}
+			samples[b.Name][v] = append(samples[b.Name][v], value)
+			versions[v] = true
+		}
+	}
+
+	h := &History{Unit: unit, Values: make(map[string]map[string]float64)}
+	for v := range versions {
+		h.Versions = append(h.Versions, v)
+	}
+	sort.Slice(h.Versions, func(i, j int) bool {
+		return CompareVersions(h.Versions[i], h.Versions[j]) < 0
+	})
+	for name, byVersion := range samples {
+		h.Names = append(h.Names, name)
+		h.Values[name] = make(map[string]float64)
+		for v, s := range byVersion {
+			h.Values[name][v] = s.Median()
+		}
+	}
+	sort.Strings(h.Names)
+	return h
+}
+
+// afterBound reports whether v is above the upper bound to. Real toolchains
+// always report a patch number, so a bound such as go1.23 is compared by
+// major and minor version only.
+func afterBound(v, to string) bool {
+	if hasPatch(to) {
+		return CompareVersions(v, to) > 0
+	}
+	pv, pt := parseVersion(v), parseVersion(to)
+	return pv[0] > pt[0] || pv[0] == pt[0] && pv[1] > pt[1]
+}
+
+// hasPatch reports whether v names a patch release or a pre-release rather
+// than a whole minor version.
+func hasPatch(v string) bool {
+	return strings.Count(v, ".") >= 2 || strings.Contains(v, "beta") || strings.Contains(v, "rc")
+}
+
+// WriteTable writes h as a table with one row per benchmark and one column
+// per Go version, followed by the change from the first to the last version.
+func (h *History) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t", h.Unit)
+	for _, v := range h.Versions {
+		fmt.Fprintf(tw, "%s\t", v)
+	}
+	fmt.Fprintf(tw, "delta\t\n")
+
+	for _, name := range h.Names {
+		fmt.Fprintf(tw, "%s\t", name)
+		var first, last float64
+		var seen bool
+		for _, v := range h.Versions {
+			value, ok := h.Values[name][v]
+			if !ok {
+				fmt.Fprintf(tw, "-\t")
+				continue
+			}
+			if !seen {
+				first, seen = value, true
+			}
+			last = value
+			fmt.Fprintf(tw, "%s\t", bench.FormatValue(value))
+		}
+		if seen && first != 0 {
+			fmt.Fprintf(tw, "%+.1f%%\t\n", 100*(last-first)/first)
+		} else {
+			fmt.Fprintf(tw, "-\t\n")
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,205 @@
+// Package results defines the JSON format benchmark runs are saved in and a
+// directory based store of past runs.
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+)
+
+// Run is the result of a single invocation of the benchmarks along with the
+// machine and toolchain it ran on. Each run is saved as one JSON document.
+type Run struct {
+	Time       time.Time          `json:"time"`
+	Metadata   *metadata.Metadata `json:"metadata"`
+	Benchmarks []*bench.Result    `json:"benchmarks"`
+}
+
+// New returns a run of the results in set.
+func New(set *bench.Set, md *metadata.Metadata) *Run {
+	return &Run{
+		Time:       time.Now().UTC(),
+		Metadata:   md,
+		Benchmarks: set.Results,
+	}
+}
+
+// Set returns the results of r as a bench.Set.
+func (r *Run) Set() *bench.Set {
+	return &bench.Set{Config: make(map[string]string), Results: r.Benchmarks}
+}
+
+// GoVersion returns the Go version r was generated with, or the empty string
+// if it is unknown.
+func (r *Run) GoVersion() string {
+	if r.Metadata == nil {
+		return ""
+	}
+	return r.Metadata.GoVersion
+}
+
+// Load reads the run saved at path.
+func Load(path string) (*Run, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("results: %s: %v", path, err)
+	}
+	return &r, nil
+}
+
+// Read reads a run from path, which is either a saved run ending in .json or
+// raw go test -bench output. Runs read from raw output have no metadata.
+func Read(path string) (*Run, error) {
+	if strings.HasSuffix(path, ".json") {
+		return Load(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	set, err := bench.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	return New(set, nil), nil
+}
+
+// Save writes r to path.
+func (r *Run) Save(path string) error {
+	data, err := r.marshal()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func (r *Run) marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Store is a directory of saved runs.
+type Store struct {
+	Dir string
+}
+
+// Add saves r in the store and returns the path it was saved at. Runs are
+// named after their Go version and time so that they sort chronologically
+// within a version. A run started in the same second as one already in the
+// store is given a numbered suffix, e.g. go1.23.4-20240101T120000Z-2.json,
+// rather than replacing it.
+func (s Store) Add(r *Run) (string, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := r.marshal()
+	if err != nil {
+		return "", err
+	}
+	version := r.GoVersion()
+	if version == "" {
+		version = "unknown"
+	}
+	base := fmt.Sprintf("%s-%s", version, r.Time.Format("20060102T150405Z"))
+	for n := 1; ; n++ {
+		name := base + ".json"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.json", base, n)
+		}
+		path := filepath.Join(s.Dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return path, err
+	}
+}
+
+// List loads every run in the store, ordered by Go version and then time.
+func (s Store) List() ([]*Run, error) {
+	paths, err := filepath.Glob(filepath.Join(s.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*Run, 0, len(paths))
+	for _, path := range paths {
+		r, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if c := CompareVersions(runs[i].GoVersion(), runs[j].GoVersion()); c != 0 {
+			return c < 0
+		}
+		return runs[i].Time.Before(runs[j].Time)
+	})
+	return runs, nil
+}
+
+// CompareVersions compares two Go versions such as go1.9.3 and go1.21rc2,
+// returning -1, 0 or 1. Pre-releases sort before the release they precede.
+func CompareVersions(a, b string) int {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseVersion splits a Go version into its major, minor and patch numbers
+// followed by a pre-release rank, where releases rank above any beta or
+// release candidate.
+func parseVersion(v string) [5]int {
+	var p [5]int
+	v = strings.TrimPrefix(v, "go")
+	if i := strings.IndexAny(v, " -"); i >= 0 {
+		v = v[:i]
+	}
+	p[3], p[4] = 2, 0
+	for _, pre := range []struct {
+		sep  string
+		rank int
+	}{{"beta", 0}, {"rc", 1}} {
+		if i := strings.Index(v, pre.sep); i >= 0 {
+			p[3] = pre.rank
+			p[4], _ = strconv.Atoi(v[i+len(pre.sep):])
+			v = v[:i]
+		}
+	}
+	for i, part := range strings.SplitN(v, ".", 3) {
+		p[i], _ = strconv.Atoi(part)
+	}
+	return p
+}
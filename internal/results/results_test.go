@@ -0,0 +1,80 @@
+package results
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, -1, CompareVersions("go1.9.3", "go1.18"))
+	assert.Equal(t, -1, CompareVersions("go1.21rc2", "go1.21.0"))
+	assert.Equal(t, -1, CompareVersions("go1.21beta1", "go1.21rc1"))
+	assert.Equal(t, 1, CompareVersions("go1.23.1", "go1.23"))
+	assert.Equal(t, 0, CompareVersions("go1.20", "go1.20.0"))
+}
+
+func TestQueryMinorBound(t *testing.T) {
+	var runs []*Run
+	for i, v := range []string{"go1.18.1", "go1.23.0", "go1.23.4", "go1.24.0"} {
+		runs = append(runs, &Run{
+			Metadata:   &metadata.Metadata{GoVersion: v},
+			Benchmarks: []*bench.Result{{Name: "BenchmarkInterfaceMethodCall", NsPerOp: float64(i + 1)}},
+		})
+	}
+	re := regexp.MustCompile("Interface")
+	assert.Equal(t, []string{"go1.18.1", "go1.23.0", "go1.23.4"}, Query(runs, re, "ns/op", "go1.18", "go1.23").Versions)
+	assert.Equal(t, []string{"go1.23.0"}, Query(runs, re, "ns/op", "go1.23", "go1.23.0").Versions)
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := Store{Dir: dir}
+	for i, v := range []string{"go1.23.0", "go1.18.1", "go1.23.0"} {
+		r := &Run{
+			Time:     time.Date(2024, 1, i+1, 0, 0, 0, 0, time.UTC),
+			Metadata: &metadata.Metadata{GoVersion: v},
+			Benchmarks: []*bench.Result{
+				{Name: "BenchmarkInterfaceMethodCall", NsPerOp: float64(10 - i)},
+			},
+		}
+		_, err := store.Add(r)
+		assert.NoError(t, err)
+	}
+
+	runs, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, runs, 3)
+
+	// A second run in the same second doesn't replace the first.
+	first, err := store.Add(runs[0])
+	assert.NoError(t, err)
+	second, err := store.Add(runs[0])
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.True(t, strings.HasSuffix(second, "-3.json"), second)
+	dup, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, dup, 5)
+	assert.Equal(t, "go1.18.1", runs[0].GoVersion())
+
+	h := Query(runs, regexp.MustCompile("Interface"), "ns/op", "", "")
+	assert.Equal(t, []string{"go1.18.1", "go1.23.0"}, h.Versions)
+	assert.Equal(t, 9.0, h.Values["BenchmarkInterfaceMethodCall"]["go1.18.1"])
+	assert.Equal(t, 9.0, h.Values["BenchmarkInterfaceMethodCall"]["go1.23.0"])
+
+	var buf bytes.Buffer
+	assert.NoError(t, h.WriteTable(&buf))
+	assert.True(t, strings.Contains(buf.String(), "+0.0%"))
+}
@@ -0,0 +1,142 @@
+// Package scaling measures how the throughput of parallel benchmarks scales
+// with GOMAXPROCS.
+package scaling
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+)
+
+// Procs returns the GOMAXPROCS values to sweep: powers of two up to max,
+// followed by max itself if it isn't a power of two. max must be at least 1.
+func Procs(max int) ([]int, error) {
+	if max < 1 {
+		return nil, fmt.Errorf("scaling: the largest GOMAXPROCS must be at least 1, got %d", max)
+	}
+	var procs []int
+	for p := 1; p <= max; p *= 2 {
+		procs = append(procs, p)
+	}
+	if procs[len(procs)-1] != max {
+		procs = append(procs, max)
+	}
+	return procs, nil
+}
+
+// CPUFlag formats procs as the value of go test -cpu.
+func CPUFlag(procs []int) string {
+	s := make([]string, len(procs))
+	for i, p := range procs {
+		s[i] = strconv.Itoa(p)
+	}
+	return strings.Join(s, ",")
+}
+
+// Curve is the throughput of a single benchmark at each GOMAXPROCS value.
+type Curve struct {
+	Name  string
+	Procs []int
+	// Throughput is the median number of operations per second at the
+	// corresponding entry of Procs.
+	Throughput []float64
+}
+
+// Speedup returns the throughput at the i'th proc count relative to the
+// throughput at the first.
+func (c *Curve) Speedup(i int) float64 {
+	return c.Throughput[i] / c.Throughput[0]
+}
+
+// Knee returns the proc count after which adding more procs stops improving
+// throughput by at least minGain, e.g. 0.1 for 10%. It is the point where
+// contention starts to dominate.
+func (c *Curve) Knee(minGain float64) int {
+	knee := c.Procs[0]
+	for i := 1; i < len(c.Procs); i++ {
+		if c.Throughput[i] < (1+minGain)*c.Throughput[i-1] {
+			break
+		}
+		knee = c.Procs[i]
+	}
+	return knee
+}
+
+// Curves builds a curve for every benchmark in set, in the order they first
+// appear. Results sampled several times at the same proc count are reduced to
+// their median.
+func Curves(set *bench.Set) []*Curve {
+	var names []string
+	samples := make(map[string]map[int]compare.Sample)
+	for _, r := range set.Results {
+		if r.NsPerOp <= 0 {
+			continue
+		}
+		if samples[r.Name] == nil {
+			samples[r.Name] = make(map[int]compare.Sample)
+			names = append(names, r.Name)
+		}
+		samples[r.Name][r.Procs] = append(samples[r.Name][r.Procs], r.NsPerOp)
+	}
+
+	curves := make([]*Curve, 0, len(names))
+	for _, name := range names {
+		c := &Curve{Name: name}
+		for p := range samples[name] {
+			c.Procs = append(c.Procs, p)
+		}
+		sort.Ints(c.Procs)
+		for _, p := range c.Procs {
+			c.Throughput = append(c.Throughput, 1e9/samples[name][p].Median())
+		}
+		curves = append(curves, c)
+	}
+	return curves
+}
+
+// WriteTable writes the throughput of each curve in millions of operations
+// per second at each proc count, followed by the speedup at the largest
+// proc count and the knee.
+func WriteTable(w io.Writer, curves []*Curve, minGain float64) error {
+	var procs []int
+	seen := make(map[int]bool)
+	for _, c := range curves {
+		for _, p := range c.Procs {
+			if !seen[p] {
+				seen[p] = true
+				procs = append(procs, p)
+			}
+		}
+	}
+	sort.Ints(procs)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Mops/s\t")
+	for _, p := range procs {
+		fmt.Fprintf(tw, "procs=%d\t", p)
+	}
+	fmt.Fprintf(tw, "speedup\tknee\n")
+	for _, c := range curves {
+		fmt.Fprintf(tw, "%s\t", c.Name)
+		byProcs := make(map[int]float64, len(c.Procs))
+		for i, p := range c.Procs {
+			byProcs[p] = c.Throughput[i]
+		}
+		for _, p := range procs {
+			if t, ok := byProcs[p]; ok {
+				fmt.Fprintf(tw, "%s\t", bench.FormatValue(t/1e6))
+			} else {
+				fmt.Fprintf(tw, "-\t")
+			}
+		}
+		last := len(c.Procs) - 1
+		fmt.Fprintf(tw, "%.2fx\t%d\n", c.Speedup(last), c.Knee(minGain))
+	}
+	return tw.Flush()
+}
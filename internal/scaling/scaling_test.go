@@ -0,0 +1,53 @@
+package scaling
+
+import (
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcs(t *testing.T) {
+	for _, tc := range []struct {
+		max  int
+		want []int
+	}{
+		{1, []int{1}},
+		{8, []int{1, 2, 4, 8}},
+		{6, []int{1, 2, 4, 6}},
+	} {
+		procs, err := Procs(tc.max)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, procs)
+	}
+	assert.Equal(t, "1,2,4,6", CPUFlag([]int{1, 2, 4, 6}))
+
+	for _, max := range []int{0, -1} {
+		_, err := Procs(max)
+		assert.Error(t, err)
+	}
+}
+
+func TestCurves(t *testing.T) {
+	set := &bench.Set{Results: []*bench.Result{
+		{Name: "BenchmarkMutexLock", Procs: 1, NsPerOp: 20},
+		{Name: "BenchmarkMutexLock", Procs: 2, NsPerOp: 12},
+		{Name: "BenchmarkMutexLock", Procs: 4, NsPerOp: 11},
+		{Name: "BenchmarkMutexLock", Procs: 8, NsPerOp: 30},
+		{Name: "BenchmarkLocalRandInt63", Procs: 1, NsPerOp: 8},
+		{Name: "BenchmarkLocalRandInt63", Procs: 2, NsPerOp: 4},
+		{Name: "BenchmarkLocalRandInt63", Procs: 4, NsPerOp: 2},
+		{Name: "BenchmarkLocalRandInt63", Procs: 8, NsPerOp: 1},
+	}}
+	curves := Curves(set)
+	assert.Len(t, curves, 2)
+
+	mutex := curves[0]
+	assert.Equal(t, []int{1, 2, 4, 8}, mutex.Procs)
+	assert.Equal(t, 2, mutex.Knee(0.1))
+	assert.InDelta(t, 20.0/30, mutex.Speedup(3), 1e-9)
+
+	local := curves[1]
+	assert.Equal(t, 8, local.Knee(0.1))
+	assert.InDelta(t, 8, local.Speedup(3), 1e-9)
+}
@@ -0,0 +1,112 @@
+// Package stability repeats benchmark runs until their results settle.
+//
+// Short benchmarks are at the mercy of everything else the machine is doing.
+// Rather than trusting a fixed number of samples, each benchmark is sampled
+// until the coefficient of variation of its ns/op falls below a target, and
+// benchmarks which don't settle within a budget are reported as unstable
+// instead of being reported as if they had.
+package stability
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/jeromefroe/golang_benchmarks/internal/compare"
+)
+
+// Options configures Sample.
+type Options struct {
+	// Warmup is the number of runs to discard before sampling.
+	Warmup int
+	// Target is the coefficient of variation below which a benchmark is
+	// considered stable, e.g. 0.02 for 2%.
+	Target float64
+	// MinRuns and MaxRuns bound the number of samples taken.
+	MinRuns, MaxRuns int
+	// Budget bounds the time spent sampling, warmup included.
+	Budget time.Duration
+}
+
+// DefaultOptions are the options used by benchrun stable.
+var DefaultOptions = Options{
+	Warmup:  1,
+	Target:  0.02,
+	MinRuns: 5,
+	MaxRuns: 30,
+	Budget:  time.Minute,
+}
+
+// Result is the samples of a benchmark and whether they settled.
+type Result struct {
+	Name    string
+	Samples compare.Sample
+	Stable  bool
+}
+
+// CV returns the coefficient of variation of the samples.
+func (r *Result) CV() float64 { return r.Samples.CV() }
+
+// Status returns "stable" or "unstable".
+func (r *Result) Status() string {
+	if r.Stable {
+		return "stable"
+	}
+	return "unstable"
+}
+
+// Sample calls run, which should run a benchmark once and return its
+// results, until every benchmark it reports is stable or the runs or time
+// budget is exhausted. A run may report several results, e.g. one per
+// sub-benchmark, and stops only when all of them are stable. Results are
+// returned in the order run first reported them.
+func Sample(run func() (*bench.Set, error), opts Options) ([]*Result, error) {
+	start := time.Now()
+	for i := 0; i < opts.Warmup; i++ {
+		if _, err := run(); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []*Result
+	byName := make(map[string]*Result)
+	for runs := 0; runs < opts.MaxRuns; runs++ {
+		set, err := run()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range set.Results {
+			res := byName[r.Name]
+			if res == nil {
+				res = &Result{Name: r.Name}
+				byName[r.Name] = res
+				results = append(results, res)
+			}
+			res.Samples = append(res.Samples, r.NsPerOp)
+		}
+
+		stable := len(results) > 0
+		for _, res := range results {
+			res.Stable = len(res.Samples) >= opts.MinRuns && res.CV() <= opts.Target
+			stable = stable && res.Stable
+		}
+		if stable || time.Since(start) >= opts.Budget {
+			break
+		}
+	}
+	return results, nil
+}
+
+// WriteTable writes a row per result with its median ns/op, coefficient of
+// variation and whether it is stable.
+func WriteTable(w io.Writer, results []*Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "benchmark\truns\tns/op\tcv\tstatus")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.1f%%\t%s\n",
+			r.Name, len(r.Samples), bench.FormatValue(r.Samples.Median()), 100*r.CV(), r.Status())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,51 @@
+package stability
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/bench"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRun returns a run func which reports the given ns/op values for a
+// stable and a noisy benchmark, one per call.
+func fakeRun(calls *int, noisy []float64) func() (*bench.Set, error) {
+	return func() (*bench.Set, error) {
+		i := *calls
+		*calls++
+		return &bench.Set{Results: []*bench.Result{
+			{Name: "BenchmarkSteady", NsPerOp: 100},
+			{Name: "BenchmarkNoisy", NsPerOp: noisy[i%len(noisy)]},
+		}}, nil
+	}
+}
+
+func TestSampleStable(t *testing.T) {
+	calls := 0
+	results, err := Sample(fakeRun(&calls, []float64{50}), Options{Warmup: 2, Target: 0.02, MinRuns: 5, MaxRuns: 30, Budget: time.Minute})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, calls)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "BenchmarkSteady", results[0].Name)
+		assert.Len(t, results[0].Samples, 5)
+		assert.True(t, results[0].Stable)
+		assert.True(t, results[1].Stable)
+	}
+}
+
+func TestSampleUnstable(t *testing.T) {
+	calls := 0
+	results, err := Sample(fakeRun(&calls, []float64{50, 80, 20}), Options{Target: 0.02, MinRuns: 5, MaxRuns: 12, Budget: time.Minute})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, calls)
+	assert.True(t, results[0].Stable)
+	assert.False(t, results[1].Stable)
+	assert.Equal(t, "unstable", results[1].Status())
+}
+
+func TestSampleError(t *testing.T) {
+	_, err := Sample(func() (*bench.Set, error) { return nil, errors.New("boom") }, DefaultOptions)
+	assert.Error(t, err)
+}
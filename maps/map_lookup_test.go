@@ -1,8 +1,10 @@
-package main
+package maps
 
 import (
 	"math/rand"
 	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
 )
 
 const (
@@ -10,10 +12,6 @@ const (
 	benchSetSize = 1024
 )
 
-var (
-	benchPlaceholder bool
-)
-
 func BenchmarkMapUint64(b *testing.B) {
 	set := make(map[uint64]struct{}, benchSetSize)
 	keys := make([]uint64, 0, benchSetSize)
@@ -26,29 +24,23 @@ func BenchmarkMapUint64(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		for _, k := range keys {
-			_, benchPlaceholder = set[k]
+			_, harness.Sink.Bool = set[k]
 		}
 	}
 }
 
-func BenchmarkMapString1(b *testing.B) {
-	benchmarkString(b, 1)
-}
-
-func BenchmarkMapString10(b *testing.B) {
-	benchmarkString(b, 10)
-}
-
-func BenchmarkMapString100(b *testing.B) {
-	benchmarkString(b, 100)
-}
-
-func BenchmarkMapString1000(b *testing.B) {
-	benchmarkString(b, 1000)
-}
-
-func BenchmarkMapString10000(b *testing.B) {
-	benchmarkString(b, 10000)
+func BenchmarkMapString(b *testing.B) {
+	axes := []harness.Axis{{Name: "keylen", Values: []int{1, 10, 100, 1000, 10000}}}
+	harness.Sweep(b, axes, func(p harness.Params) func(b *testing.B) {
+		set, keys := genStringSet(p.Int("keylen"))
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, k := range keys {
+					_, harness.Sink.Bool = set[k]
+				}
+			}
+		}
+	})
 }
 
 func genString(n int) string {
@@ -59,7 +51,9 @@ func genString(n int) string {
 	return string(b)
 }
 
-func benchmarkString(b *testing.B, n int) {
+// genStringSet returns a set of benchSetSize random strings of length n and
+// its keys.
+func genStringSet(n int) (map[string]struct{}, []string) {
 	set := make(map[string]struct{}, benchSetSize)
 	keys := make([]string, 0, benchSetSize)
 	for i := 0; i < benchSetSize; i++ {
@@ -67,11 +61,5 @@ func benchmarkString(b *testing.B, n int) {
 		set[key] = struct{}{}
 		keys = append(keys, key)
 	}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		for _, k := range keys {
-			_, benchPlaceholder = set[k]
-		}
-	}
+	return set, keys
 }
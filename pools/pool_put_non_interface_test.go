@@ -1,9 +1,10 @@
-package main
+package pools
 
 import (
 	"sync"
 	"testing"
 
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
 	"github.com/m3db/m3x/pool"
 )
 
@@ -13,6 +14,7 @@ func BenchmarkPoolM3XPutSlice(b *testing.B) {
 		return make([]byte, 0, 1024)
 	})
 	b.ResetTimer()
+	defer harness.RecordGC(b).Stop()
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
@@ -36,6 +38,7 @@ func BenchmarkPoolM3XPutPointerToSlice(b *testing.B) {
 		return &b
 	})
 	b.ResetTimer()
+	defer harness.RecordGC(b).Stop()
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
@@ -59,6 +62,7 @@ func BenchmarkPoolSyncPutSlice(b *testing.B) {
 		},
 	}
 	b.ResetTimer()
+	defer harness.RecordGC(b).Stop()
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
@@ -83,6 +87,7 @@ func BenchmarkPoolSyncPutPointerToSlice(b *testing.B) {
 		},
 	}
 	b.ResetTimer()
+	defer harness.RecordGC(b).Stop()
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
@@ -1,9 +1,11 @@
-package main
+package pools
 
 import (
 	"bytes"
 	"sync"
 	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
 )
 
 type ChannelBufferPool struct {
@@ -37,6 +39,7 @@ func (p *ChannelBufferPool) Put(b *bytes.Buffer) {
 }
 
 func BenchmarkAllocateBufferNoPool(b *testing.B) {
+	defer harness.RecordGC(b).Stop()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
 			buf := bytes.NewBuffer(make([]byte, 0, 256))
@@ -48,6 +51,7 @@ func BenchmarkAllocateBufferNoPool(b *testing.B) {
 func BenchmarkChannelBufferPool(b *testing.B) {
 	p := NewChannelBufferPool(1, 256)
 	b.ResetTimer()
+	defer harness.RecordGC(b).Stop()
 
 	b.RunParallel(func(pb *testing.PB) {
 
@@ -66,6 +70,7 @@ func BenchmarkSyncBufferPool(b *testing.B) {
 			return bytes.NewBuffer(make([]byte, 0, 256))
 		},
 	}
+	defer harness.RecordGC(b).Stop()
 
 	b.RunParallel(func(pb *testing.PB) {
 
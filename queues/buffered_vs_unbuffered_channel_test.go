@@ -0,0 +1,41 @@
+package queues
+
+import (
+	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
+)
+
+func BenchmarkSynchronousChannel(b *testing.B) {
+	ch := make(chan int)
+	lat := harness.NewLatencyRecorder()
+	go func() {
+		for i := 0; i < b.N; i++ {
+			ch <- lat.Item(i)
+		}
+		close(ch)
+	}()
+	h := lat.Local()
+	for v := range ch {
+		lat.Record(h, v)
+	}
+	lat.Merge(h)
+	lat.Report(b)
+}
+
+func BenchmarkBufferedChannel(b *testing.B) {
+	ch := make(chan int, 128)
+	lat := harness.NewLatencyRecorder()
+	go func() {
+		for i := 0; i < b.N; i++ {
+			ch <- lat.Item(i)
+		}
+		close(ch)
+	}()
+	h := lat.Local()
+	for v := range ch {
+		lat.Record(h, v)
+	}
+	lat.Merge(h)
+	lat.Report(b)
+}
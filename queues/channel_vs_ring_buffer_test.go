@@ -1,106 +1,126 @@
-package main
+package queues
 
 import (
 	"sync"
 	"testing"
 
 	"github.com/Workiva/go-datastructures/queue"
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
 )
 
 func BenchmarkChannelSPSC(b *testing.B) {
 	ch := make(chan interface{}, 128)
+	lat := harness.NewLatencyRecorder()
 	var wg sync.WaitGroup
 	wg.Add(1)
 	b.ResetTimer()
 
 	go func() {
 		for i := 0; i < b.N; i++ {
-			ch <- 100
+			ch <- lat.Value(100)
 		}
 	}()
 
 	go func() {
+		h := lat.Local()
 		for i := 0; i < b.N; i++ {
-			<-ch
+			lat.RecordValue(h, <-ch)
 		}
+		lat.Merge(h)
 		wg.Done()
 	}()
 
 	wg.Wait()
+	lat.Report(b)
 }
 
 func BenchmarkRingBufferSPSC(b *testing.B) {
 	q := queue.NewRingBuffer(128)
+	lat := harness.NewLatencyRecorder()
 	var wg sync.WaitGroup
 	wg.Add(1)
 	b.ResetTimer()
 
 	go func() {
 		for i := 0; i < b.N; i++ {
-			q.Put(100)
+			q.Put(lat.Value(100))
 		}
 	}()
 
 	go func() {
+		h := lat.Local()
 		for i := 0; i < b.N; i++ {
-			q.Get()
+			v, _ := q.Get()
+			lat.RecordValue(h, v)
 		}
+		lat.Merge(h)
 		wg.Done()
 	}()
 
 	wg.Wait()
+	lat.Report(b)
 }
 
 func BenchmarkChannelSPMC(b *testing.B) {
 	ch := make(chan interface{}, 128)
+	lat := harness.NewLatencyRecorder()
 	var wg sync.WaitGroup
 	wg.Add(1000)
 	b.ResetTimer()
 
 	go func() {
 		for i := 0; i < b.N; i++ {
-			ch <- 100
+			ch <- lat.Value(100)
 		}
 	}()
 
 	for i := 0; i < 1000; i++ {
 		go func() {
+			h := lat.Local()
 			for i := 0; i < b.N/1000; i++ {
-				<-ch
+				lat.RecordValue(h, <-ch)
 			}
+			lat.Merge(h)
 			wg.Done()
 		}()
 	}
 
 	wg.Wait()
+	lat.Report(b)
 }
 
 func BenchmarkRingBufferSPMC(b *testing.B) {
 	q := queue.NewRingBuffer(128)
+	lat := harness.NewLatencyRecorder()
 	var wg sync.WaitGroup
 	wg.Add(1000)
 	b.ResetTimer()
 
 	go func() {
 		for i := 0; i < b.N; i++ {
-			q.Put(100)
+			q.Put(lat.Value(100))
 		}
 	}()
 
 	for i := 0; i < 1000; i++ {
 		go func() {
+			h := lat.Local()
 			for i := 0; i < b.N/1000; i++ {
-				q.Get()
+				v, _ := q.Get()
+				lat.RecordValue(h, v)
 			}
+			lat.Merge(h)
 			wg.Done()
 		}()
 	}
 
 	wg.Wait()
+	lat.Report(b)
 }
 
 func BenchmarkChannelMPSC(b *testing.B) {
 	ch := make(chan interface{}, 128)
+	lat := harness.NewLatencyRecorder()
 	var wg sync.WaitGroup
 	wg.Add(1)
 	b.ResetTimer()
@@ -108,23 +128,27 @@ func BenchmarkChannelMPSC(b *testing.B) {
 	for i := 0; i < 1000; i++ {
 		go func() {
 			for i := 0; i < b.N; i++ {
-				ch <- 100
+				ch <- lat.Value(100)
 			}
 		}()
 	}
 
 	go func() {
+		h := lat.Local()
 		for i := 0; i < b.N; i++ {
-			<-ch
+			lat.RecordValue(h, <-ch)
 		}
+		lat.Merge(h)
 		wg.Done()
 	}()
 
 	wg.Wait()
+	lat.Report(b)
 }
 
 func BenchmarkRingBufferMPSC(b *testing.B) {
 	q := queue.NewRingBuffer(128)
+	lat := harness.NewLatencyRecorder()
 	var wg sync.WaitGroup
 	wg.Add(1)
 	b.ResetTimer()
@@ -132,23 +156,28 @@ func BenchmarkRingBufferMPSC(b *testing.B) {
 	for i := 0; i < 1000; i++ {
 		go func() {
 			for i := 0; i < b.N; i++ {
-				q.Put(100)
+				q.Put(lat.Value(100))
 			}
 		}()
 	}
 
 	go func() {
+		h := lat.Local()
 		for i := 0; i < b.N; i++ {
-			q.Get()
+			v, _ := q.Get()
+			lat.RecordValue(h, v)
 		}
+		lat.Merge(h)
 		wg.Done()
 	}()
 
 	wg.Wait()
+	lat.Report(b)
 }
 
 func BenchmarkChannelMPMC(b *testing.B) {
 	ch := make(chan interface{}, 128)
+	lat := harness.NewLatencyRecorder()
 	var wg sync.WaitGroup
 	wg.Add(1000)
 	b.ResetTimer()
@@ -156,25 +185,29 @@ func BenchmarkChannelMPMC(b *testing.B) {
 	for i := 0; i < 1000; i++ {
 		go func() {
 			for i := 0; i < b.N; i++ {
-				ch <- 100
+				ch <- lat.Value(100)
 			}
 		}()
 	}
 
 	for i := 0; i < 1000; i++ {
 		go func() {
+			h := lat.Local()
 			for i := 0; i < b.N; i++ {
-				<-ch
+				lat.RecordValue(h, <-ch)
 			}
+			lat.Merge(h)
 			wg.Done()
 		}()
 	}
 
 	wg.Wait()
+	lat.Report(b)
 }
 
 func BenchmarkRingBufferMPMC(b *testing.B) {
 	q := queue.NewRingBuffer(128)
+	lat := harness.NewLatencyRecorder()
 	var wg sync.WaitGroup
 	wg.Add(1000)
 	b.ResetTimer()
@@ -182,19 +215,23 @@ func BenchmarkRingBufferMPMC(b *testing.B) {
 	for i := 0; i < 1000; i++ {
 		go func() {
 			for i := 0; i < b.N; i++ {
-				q.Put(100)
+				q.Put(lat.Value(100))
 			}
 		}()
 	}
 
 	for i := 0; i < 1000; i++ {
 		go func() {
+			h := lat.Local()
 			for i := 0; i < b.N; i++ {
-				q.Get()
+				v, _ := q.Get()
+				lat.RecordValue(h, v)
 			}
+			lat.Merge(h)
 			wg.Done()
 		}()
 	}
 
 	wg.Wait()
+	lat.Report(b)
 }
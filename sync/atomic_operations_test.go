@@ -1,25 +1,31 @@
-package main
+package sync
 
 import (
 	"sync/atomic"
 	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
 )
 
 func BenchmarkAtomicLoad32(b *testing.B) {
 	var v int32
 	b.RunParallel(func(pb *testing.PB) {
+		var sum int32
 		for pb.Next() {
-			atomic.LoadInt32(&v)
+			sum += atomic.LoadInt32(&v)
 		}
+		atomic.StoreInt32(&harness.Sink.Int32, sum)
 	})
 }
 
 func BenchmarkAtomicLoad64(b *testing.B) {
 	var v int64
 	b.RunParallel(func(pb *testing.PB) {
+		var sum int64
 		for pb.Next() {
-			atomic.LoadInt64(&v)
+			sum += atomic.LoadInt64(&v)
 		}
+		atomic.StoreInt64(&harness.Sink.Int64, sum)
 	})
 }
 
@@ -1,9 +1,11 @@
-package main
+package sync
 
 import (
 	"runtime"
 	"sync"
 	"testing"
+
+	"github.com/jeromefroe/golang_benchmarks/internal/harness"
 )
 
 var (
@@ -17,6 +19,7 @@ type paddedInt64 struct {
 }
 
 func BenchmarkIncrementFalseSharing(b *testing.B) {
+	defer harness.RecordPerf(b).Stop()
 	for i := 0; i < b.N; i++ {
 		var (
 			wg   sync.WaitGroup
@@ -38,6 +41,7 @@ func BenchmarkIncrementFalseSharing(b *testing.B) {
 }
 
 func BenchmarkIncrementNoFalseSharing(b *testing.B) {
+	defer harness.RecordPerf(b).Stop()
 	for i := 0; i < b.N; i++ {
 		var (
 			wg   sync.WaitGroup
@@ -59,6 +63,7 @@ func BenchmarkIncrementNoFalseSharing(b *testing.B) {
 }
 
 func BenchmarkIncrementNoFalseSharingLocalVariable(b *testing.B) {
+	defer harness.RecordPerf(b).Stop()
 	for i := 0; i < b.N; i++ {
 		var (
 			wg   sync.WaitGroup